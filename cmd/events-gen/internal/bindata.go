@@ -78,7 +78,7 @@ func (fi bindataFileInfo) Sys() interface{} {
 	return nil
 }
 
-var _tsGotemplate = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xcc\x55\x41\x8b\xdb\x3a\x10\xbe\xfb\x57\xcc\x83\x40\x6c\xc8\x9a\xbc\xab\xf3\xb2\xb7\x57\xda\x42\xb7\x85\x5d\xe8\x75\xb5\xf6\x38\x11\x55\x24\x23\x4d\xd2\x0d\x5a\xfd\xf7\x22\xcb\x56\xec\x38\x49\x2f\x0b\xad\x4e\xb1\x3d\x33\xdf\x7c\x9f\xe6\x9b\x58\xab\x99\xdc\x20\x54\x58\x73\xc9\x89\x2b\x69\x9c\x4b\xf0\xb5\x51\x9a\x80\x4b\x42\x5d\xb3\x12\xc1\xda\xfc\xe9\xd8\xe0\x03\xdb\xa1\x73\x60\x13\x00\x00\x6b\xef\x20\x24\xe7\x8f\xa4\xf7\x25\x7d\xe0\x28\x2a\x9f\x1e\xbe\xe6\x4f\x6c\xe3\x5c\xe1\x7f\xc1\x1b\xd0\xb1\x41\x53\x6a\xde\x50\x0c\xb8\x03\x94\x95\x73\x89\x4b\xac\x0d\xbf\x7a\x60\x1f\x0c\xff\x1f\x50\x92\x47\x84\x35\xf4\x6d\xce\xb8\xac\xf0\x75\x01\x33\x3c\x40\xb1\x86\xbc\x8d\x31\xce\x59\xcb\x6b\xd8\x50\xf7\x1d\x96\xce\xc1\x1b\x74\x55\xe7\xd6\xce\xf0\x90\x87\xde\xe7\xdd\xcb\xd5\x14\xea\x1b\x3b\x0a\xc5\xaa\xeb\x68\x23\x8d\x6e\x21\x06\xc0\x93\x60\xd7\x31\x3f\x32\x59\x09\xd4\xb0\x86\x34\x6d\x02\x7e\x31\xea\x66\x01\xe8\x9f\x8a\x93\x1a\x19\xac\xef\x21\x3d\x28\x5e\x65\x19\xbc\x5d\xcb\x1b\x46\x45\x59\x4b\xc1\x8c\x09\x61\xa6\xbb\xc4\x46\xf3\x03\x23\x04\x43\xaa\x69\xd0\x93\xaf\x99\x30\xb8\x1a\x7d\xd4\xc8\x2a\x25\xc5\x11\x04\x37\x84\x12\xb5\x81\x35\x48\xfc\x09\x5f\x58\xf3\x5f\x6c\x6c\x31\xa2\x74\x9f\x66\xab\xa4\xad\x52\x2a\x69\xda\xf9\x50\x3a\x9d\x54\xdc\x6b\x51\x80\x21\xcd\xe5\x66\x31\xc5\xd3\x58\x2a\x29\xb1\xa4\x4f\x7e\x12\x0f\x4c\x14\x20\xf7\xbb\x97\x56\xb0\x7f\x97\xcb\x65\xd6\xb1\xf0\x87\xb6\xdc\xe4\x86\x98\x26\x8f\xec\xdf\xb8\x80\xaf\x64\x7a\xae\xe1\x02\xb6\xa1\xcb\x62\xd4\xf3\xa4\x5c\xe4\x9b\x1b\xa4\x50\x25\xa6\x66\x23\x8c\xba\x7e\x07\x90\x0a\x05\x12\x86\x42\xb1\x7c\x3f\x8c\x71\xd6\x3b\x52\xd6\x76\x23\x9d\x46\x98\xc1\x2c\x8c\x0c\x7b\x7d\x64\x46\x61\xb7\x67\xed\x37\xe2\xcc\x63\x3f\xf3\x48\x1c\x98\x19\x53\x5f\x9d\x69\xf6\x17\x71\xe8\xb4\x1f\xd0\x38\x75\x1b\xd7\x93\x7f\xf6\x4e\x49\x2f\x0c\x5e\xef\x1f\xd2\x7b\x1c\xf1\x3c\x59\xac\x9d\xcd\x41\x26\xaf\x21\x1d\x66\x67\xa0\x91\xf6\x5a\xae\x62\x84\x40\x02\x8d\x6d\xe6\xb9\x39\xa3\xb5\xc0\xa8\xf2\x07\x52\x67\xc9\xef\xf8\xf2\xd8\x3e\x87\xca\x7b\x2d\xb2\x53\x7c\x88\xcc\x95\x2c\x85\x32\x7e\xaf\xa6\xad\x3a\xa7\x8e\xfa\xae\xfe\x89\xa0\xd9\xd9\x57\x7f\x86\x1d\x9d\xe8\x0e\x8f\x41\x7a\xe2\x3b\x54\x7b\x4a\x03\xc4\xd0\x9d\x8b\xf0\x34\x31\x77\x36\xae\xe3\x92\xe9\xaf\x48\x00\xb5\x56\xed\xda\xc4\x0b\x0c\xbc\x2c\x4a\x60\xde\x06\xa5\x78\x56\xf7\x5d\xf8\x85\x3e\x5a\x19\xd3\xec\x0f\xf0\xdf\xa1\x31\x6c\xd3\x5e\xa1\xad\x18\x31\x77\x45\x06\x02\xdb\xad\xad\xce\x2f\x0e\xd6\xf0\xf9\xf1\xeb\x43\xde\x30\x6d\x30\xf5\xb9\x99\xf7\xa9\xed\x8d\x13\x97\x71\xef\x2f\x26\x8f\xe0\x56\x17\x2a\x6f\xe3\x9f\xd7\x99\x97\x36\xfd\xb2\x8c\x0b\xa0\x35\xe2\xf4\x1a\xb6\xd3\x95\xd8\x1f\xd2\xc7\xe9\x4b\x7f\xba\x9c\xde\xff\x9d\xe1\x6f\x21\xb5\x12\x42\xc9\xa8\xdc\xfa\x71\xb9\x5c\x76\x3c\x33\xcf\x35\xe3\x02\x2b\x20\x05\x8d\x56\x25\x1a\x13\xd9\xd6\x4a\x77\x90\xb3\x20\xad\x2b\x9e\x17\x70\x11\xf3\xe6\x75\xba\x24\x71\xbf\x02\x00\x00\xff\xff\xbb\xab\x8b\x58\x7e\x09\x00\x00")
+var _tsGotemplate = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xc5\x56\x4d\x8b\xdb\x30\x10\xbd\xe7\x57\xa8\xb0\x34\x36\xa4\x26\xbd\x3a\x4d\x4b\x0f\x5b\xda\x42\xb7\x0b\xbb\xd0\x43\x29\x54\xb1\xc7\x89\x59\x47\x32\x92\x92\xd6\x68\xfd\xdf\x3b\x92\x6c\xf9\x2b\x09\xdb\x52\x58\x5f\x12\xdb\xa3\x79\x6f\x9e\x66\x9e\xac\xb5\xa0\x6c\x0b\x24\x85\x2c\x67\xb9\xca\x39\x93\x75\x3d\xd3\xfa\x15\xc9\x33\x12\x7d\x92\x77\x4a\x1c\x12\x85\x8f\xe0\x77\xc9\x85\x22\x39\x53\x20\x32\x9a\x00\xd1\x3a\xba\xaf\x4a\xb8\xa1\x7b\xa8\x6b\xa2\x67\x04\x2f\xb3\xce\xe5\x8b\xdc\xc2\x0f\x39\x14\xa9\xc9\xe8\xde\x46\xf7\x74\x5b\xd7\xb1\xf9\x47\x1e\x89\xc2\xe5\x32\x11\x79\xa9\x7c\xc0\x2b\x02\x2c\xc5\x3b\x47\x01\x0a\x09\x1d\xb4\x09\x1f\xa1\xae\x27\x99\xcc\xcb\xba\x5e\xcd\xba\x54\x5a\xbb\xdf\x41\x9a\xeb\x23\x30\x65\x92\xd8\x14\x8e\xf2\x55\xce\x52\xf8\xbd\x20\x57\x70\x24\xf1\x9a\x44\x36\x06\xb9\x6b\x8d\x52\x6c\x55\xf3\x9e\x2c\x11\xf7\x91\x34\x59\xe7\x5a\x63\x78\xe4\xe8\xcc\x9b\x87\xab\x29\xd4\x2d\xad\x0a\x4e\xd3\xf3\x68\x03\xfd\x2f\x21\x3a\xc0\x4e\x83\xf3\x98\x1f\x29\x4b\x0b\x10\x88\x19\x04\xa5\xc3\x8f\x07\x6c\x16\x04\xcc\x5d\xdc\xa9\x11\x92\xf5\x5b\x12\x1c\x79\x9e\x86\x21\x42\x9e\x59\xd7\x8f\xf2\xb2\x26\x05\x95\xd2\x85\xc9\xa6\x1b\x4a\x91\x1f\xa9\x02\x22\x15\x2f\x4b\x30\xc5\x67\x14\x77\x74\x35\x7c\xc9\x93\x07\x50\xef\x62\xf2\x0d\x36\x77\xf6\xff\xf0\xbd\x00\x9a\x72\x56\x54\xa4\xc8\xa5\x02\x06\x42\x62\x1e\x06\xbf\xc8\x17\x5a\xbe\xf1\xc4\x17\x83\x92\xdf\x06\xe1\x6a\x66\xb3\x24\xa8\xa7\x6d\x44\x2e\x82\x49\xc6\x83\x28\x62\x24\x27\x72\xb6\x5d\x4c\xf1\x04\xe0\x62\x06\x89\xfa\x64\x5a\xfe\x48\x31\x96\x1d\xf6\x1b\x2b\xe8\xeb\xe5\x72\x19\x36\x55\x9a\x4b\xed\x72\x19\x49\x45\x85\x32\xc8\xe6\x49\xed\xf0\x39\x0b\xc6\x1a\x2f\xc8\xce\xb1\x8c\x07\x9c\xfb\xe9\x2c\x6b\x22\x0f\x1b\xd3\xd3\x1b\xab\x9c\x45\xf0\x12\x44\x3b\x2a\x5d\xe2\x06\xce\x93\xe8\x42\x24\x28\x17\xe2\x01\x7b\xb1\xd8\x5f\xc1\x8b\x0e\xa0\x0f\xee\x53\x21\x0b\x25\x78\x11\xcc\x7d\xdc\x7c\x41\xbe\xdb\x94\x3f\x7a\xa9\xea\x41\xbd\x59\xf6\x2f\x05\x1b\x3a\x23\xfa\x29\x14\xa0\xa0\x29\xf2\x32\xbf\x03\x7b\x22\xc3\x76\xf6\xfc\x68\x37\x7b\x84\x26\xe2\x86\x29\xf0\x4c\x7b\xad\x3f\xb0\x9c\xf3\x13\x32\x08\xbb\x3c\x5a\x93\xd6\xc1\x36\x99\x7b\x12\x73\x2f\x18\xa1\x72\x28\xd9\x6a\xa4\xf5\xf3\x13\xc7\xfd\xfe\x3b\xe6\xde\x8e\xcd\xbd\x71\x86\xe0\xc4\x20\xb5\x7e\x81\xa3\x0b\x83\x9a\xdb\x29\x6d\xf7\x9e\x26\xc6\x32\x63\xd2\xeb\x51\x2c\x6e\xd4\x11\xb6\x22\xd9\x2b\xe9\xfb\x8f\x93\xcd\xe7\xac\x88\xbc\x7c\x49\x7a\xb7\x91\x31\x84\xea\x4e\x19\xd4\xf5\x7a\xdd\xd9\x54\xf4\xf5\xf6\xfa\xe6\x64\x63\x36\x0b\x25\x16\x1a\x7c\xbe\xfb\x7a\x13\x39\x8f\xc9\xb3\x2a\xd0\x8e\x70\xcb\xa9\x0e\xcf\x0d\x52\xe7\x9d\xd6\x54\x4e\xd2\x75\x32\x85\xe8\x54\xea\x20\x58\x97\x08\x07\x07\x9f\xd9\x95\x63\xd7\xed\xb9\x8b\x2b\xd6\x79\xa9\x2f\xca\x65\x46\x63\x1c\xfb\x8a\x0f\x97\x3d\x8f\xb6\x5b\xe8\x8a\xe5\x8c\x97\xc0\xcc\x59\x63\xfb\xe5\xa9\x76\xf2\x5e\x08\x5a\x45\x99\xe0\xfb\xf1\xfc\x3f\x40\x25\x83\x70\xaa\xcf\x00\x33\x29\xb8\x84\x33\xa0\xd6\xe2\xbc\x0c\xe3\x8d\x32\x57\x5f\xa3\xae\xd3\xfa\x17\x7a\xe8\x7d\xbe\x07\x7e\x50\x81\x83\xe8\x1b\xfd\xc2\xdd\x4d\xce\x89\x70\x98\xa7\xbe\x54\x00\x08\xc1\xed\x09\x0d\x27\x2a\x30\x1b\xc5\x0b\x88\x6c\x10\x46\xac\xfe\x7f\x7d\x8e\x87\x95\x31\x08\x9f\xa1\xfe\x3d\x48\x49\xb7\x76\x0b\x75\x4a\x15\xad\xcf\xc8\xa0\x88\x6e\xce\xb2\xc6\xb6\xcc\xb7\x9f\x9d\xad\x92\x0a\xe4\x6e\xd6\x86\xc6\x74\x74\xeb\x5f\xfe\x5c\x6f\x6d\x8e\xb2\x8a\xd4\xab\x13\x99\x77\xfe\x3b\x69\xd4\x82\xdb\xf6\x04\xf5\x6e\x66\xfd\x70\xba\x0d\xbb\xe9\x89\xe6\x5b\x5f\x54\xd3\x87\xe6\x6a\xd6\xb4\x36\xdc\x38\xc2\x25\x24\x2b\x21\x49\xa8\x4a\x76\xa6\x5d\x4e\xa7\x1d\xf6\xcc\xcf\x8c\xe6\x05\xee\xbf\xe2\x68\x28\x3c\x41\xb1\x7d\xb5\x19\xf6\x9d\x83\xbc\x72\xd2\xd6\xf1\x4f\x24\x71\x0a\xf3\xe2\x76\xa2\x5f\xd5\x7f\x00\xfc\xb2\xd1\x5e\x45\x0c\x00\x00")
 
 func tsGotemplateBytes() ([]byte, error) {
 	return bindataRead(
@@ -93,7 +93,7 @@ func tsGotemplate() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "ts.gotemplate", size: 2430, mode: os.FileMode(420), modTime: time.Unix(1586701846, 0)}
+	info := bindataFileInfo{name: "ts.gotemplate", size: 3141, mode: os.FileMode(420), modTime: time.Unix(1586701846, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
@@ -0,0 +1,71 @@
+package internal
+
+import (
+	"encoding/json"
+
+	structview "github.com/reddec/struct-view"
+	"github.com/reddec/struct-view/deepparser"
+)
+
+const asyncAPIFile = "asyncapi.json"
+
+// GenerateSchema generates JSON Schema file per payload type and AsyncAPI document for all events.
+// Returns map of file name to content.
+func GenerateSchema(title string, result *structview.EventGeneratorResult) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	for _, event := range result.Events {
+		fileName := event.TypeName + ".schema.json"
+		if _, ok := files[fileName]; ok {
+			continue
+		}
+		jsg := deepparser.JSONSchema{RefPrefix: "#/definitions/"}
+		jsg.AddFromDir(event.TypeName, event.Dir)
+		if len(jsg.Ordered) == 0 {
+			continue
+		}
+		schema := jsg.MapDefinition(jsg.Ordered[0])
+		schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+		schema["title"] = event.TypeName
+		if len(jsg.Ordered) > 1 {
+			definitions := jsg.Definitions()
+			delete(definitions, event.TypeName)
+			schema["definitions"] = definitions
+		}
+		data, err := json.MarshalIndent(schema, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		files[fileName] = data
+	}
+
+	jsg := deepparser.JSONSchema{RefPrefix: "#/components/schemas/"}
+	channels := deepparser.Schema{}
+	for _, event := range result.Events {
+		jsg.AddFromDir(event.TypeName, event.Dir)
+		channels[event.Name] = deepparser.Schema{
+			"subscribe": deepparser.Schema{
+				"message": deepparser.Schema{
+					"name":    event.Name,
+					"payload": deepparser.Schema{"$ref": jsg.RefPrefix + event.TypeName},
+				},
+			},
+		}
+	}
+	doc := deepparser.Schema{
+		"asyncapi": "2.0.0",
+		"info": deepparser.Schema{
+			"title":   title,
+			"version": "1.0.0",
+		},
+		"channels": channels,
+		"components": deepparser.Schema{
+			"schemas": jsg.Definitions(),
+		},
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	files[asyncAPIFile] = data
+	return files, nil
+}
@@ -0,0 +1,68 @@
+package internal
+
+import (
+	"encoding/json"
+	"testing"
+
+	structview "github.com/reddec/struct-view"
+)
+
+type user struct {
+	Name    string   `json:"name"`
+	Profile *profile `json:"profile,omitempty"`
+}
+
+type profile struct {
+	Tags []string `json:"tags"`
+}
+
+type userIDs []int64
+
+func TestGenerateSchema(t *testing.T) {
+	files, err := GenerateSchema("Users", &structview.EventGeneratorResult{
+		Events: []structview.Event{
+			{Name: "UserCreated", TypeName: "user", Dir: "."},
+			{Name: "UserRemoved", TypeName: "user", Dir: "."},
+			{Name: "UsersPurged", TypeName: "userIDs", Dir: "."},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		file     string
+		path     []string
+		expected string
+	}{
+		{"user.schema.json", []string{"title"}, `"user"`},
+		{"user.schema.json", []string{"properties", "profile"}, `{"$ref":"#/definitions/profile"}`},
+		{"user.schema.json", []string{"definitions", "profile", "properties", "tags"}, `{"items":{"type":"string"},"type":"array"}`},
+		{"userIDs.schema.json", []string{"type"}, `"array"`},
+		{"userIDs.schema.json", []string{"items"}, `{"type":"integer"}`},
+		{"asyncapi.json", []string{"info", "title"}, `"Users"`},
+		{"asyncapi.json", []string{"channels", "UserRemoved", "subscribe", "message", "payload"}, `{"$ref":"#/components/schemas/user"}`},
+		{"asyncapi.json", []string{"components", "schemas", "userIDs"}, `{"items":{"type":"integer"},"type":"array"}`},
+	}
+	for _, c := range cases {
+		content, ok := files[c.file]
+		if !ok {
+			t.Errorf("%s not generated", c.file)
+			continue
+		}
+		var node interface{}
+		if err := json.Unmarshal(content, &node); err != nil {
+			t.Fatal(c.file, err)
+		}
+		for _, key := range c.path {
+			object, _ := node.(map[string]interface{})
+			node = object[key]
+		}
+		data, _ := json.Marshal(node)
+		if string(data) != c.expected {
+			t.Errorf("%s %v: %s != %s", c.file, c.path, data, c.expected)
+		}
+	}
+	if len(files) != 3 {
+		t.Error("expected 3 files, got", len(files))
+	}
+}
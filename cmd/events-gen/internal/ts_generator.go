@@ -17,6 +17,7 @@ func GenerateTS(result *structview.EventGeneratorResult) string {
 		return strings.Split(text, "\n")[0]
 	}
 	fm["typescript"] = tsg.MapField
+	fm["typescriptType"] = tsg.MapDefinition
 	fm["definitions"] = func() []*deepparser.Definition {
 		if tsg.Ordered == nil {
 			for _, event := range result.Events {
@@ -12,21 +12,36 @@ import (
 )
 
 type Config struct {
-	Package        string            `short:"p" long:"package" env:"PACKAGE" description:"Package name (can be override by output dir)" default:"events"`
-	Output         string            `short:"o" long:"output" env:"OUTPUT" description:"Generated output destination (- means STDOUT)" default:"-"`
-	Private        bool              `short:"P" long:"private" env:"PRIVATE" description:"Make generated event structures be private by prefix 'event'"`
-	PrivateEmitter bool              `short:"k" long:"private-emitter" env:"PRIVATE_EMITTER" description:"Private emitter"`
-	EventBus       string            `short:"E" long:"event-bus" env:"EVENT_BUS" description:"Generate structure that aggregates all events" default:""`
-	Mirror         bool              `short:"m" long:"mirror" env:"MIRROR" description:"Mirror all events to the universal emitter"`
-	FromMirror     bool              `short:"f" long:"from-mirror" env:"FROM_MIRROR" description:"Create producer events as from mirror (only for event bus)"`
-	IgnoreCase     bool              `short:"i" long:"ignore-case" env:"IGNORE_CASE" description:"Ignore event case for universal source (--from-mirror)"`
-	Sink           bool              `short:"s" long:"sink" env:"SINK" description:"Make a sink method for event bus to subscribe to all events"`
-	Emitter        string            `short:"e" long:"emitter" env:"EMITTER" description:"Create emitter factory"`
-	Listener       string            `short:"l" long:"listener" env:"LISTENER" description:"Create method to subscribe for all events" default:"SubscribeAll"`
-	Hint           map[string]string `short:"H" long:"hint" env:"HINT" description:"Give a hint about events (eventName -> struct name)"`
-	Context        bool              `short:"c" long:"context" env:"CONTEXT" description:"Add context to events"`
-	TS             string            `long:"ts" env:"TS" description:"Generate TypeScript supporting file"`
-	Args           struct {
+	Package          string            `short:"p" long:"package" env:"PACKAGE" description:"Package name (can be override by output dir)" default:"events"`
+	Output           string            `short:"o" long:"output" env:"OUTPUT" description:"Generated output destination (- means STDOUT)" default:"-"`
+	Private          bool              `short:"P" long:"private" env:"PRIVATE" description:"Make generated event structures be private by prefix 'event'"`
+	PrivateEmitter   bool              `short:"k" long:"private-emitter" env:"PRIVATE_EMITTER" description:"Private emitter"`
+	EventBus         string            `short:"E" long:"event-bus" env:"EVENT_BUS" description:"Generate structure that aggregates all events" default:""`
+	Mirror           bool              `short:"m" long:"mirror" env:"MIRROR" description:"Mirror all events to the universal emitter"`
+	Mirrors          bool              `long:"mirrors" env:"MIRRORS" description:"Add and remove multiple mirrors at runtime (implies --mirror, only for event bus)"`
+	FromMirror       bool              `short:"f" long:"from-mirror" env:"FROM_MIRROR" description:"Create producer events as from mirror (only for event bus)"`
+	IgnoreCase       bool              `short:"i" long:"ignore-case" env:"IGNORE_CASE" description:"Ignore event case for universal source (--from-mirror)"`
+	Sink             bool              `short:"s" long:"sink" env:"SINK" description:"Make a sink method for event bus to subscribe to all events"`
+	Emitter          string            `short:"e" long:"emitter" env:"EMITTER" description:"Create emitter factory"`
+	EmitterInterface bool              `long:"emitter-interface" env:"EMITTER_INTERFACE" description:"Generate interface and mock for emitter (requires --emitter)"`
+	Listener         string            `short:"l" long:"listener" env:"LISTENER" description:"Create method to subscribe for all events" default:"SubscribeAll"`
+	Hint             map[string]string `short:"H" long:"hint" env:"HINT" description:"Give a hint about events (eventName -> struct name)"`
+	Interface        []string          `short:"I" long:"interface" env:"INTERFACE" env-delim:"," description:"Interface which methods declare events (method name -> event, argument -> payload)"`
+	Context          bool              `short:"c" long:"context" env:"CONTEXT" description:"Add context to events"`
+	TS               string            `long:"ts" env:"TS" description:"Generate TypeScript supporting file"`
+	Schema           string            `long:"schema" env:"SCHEMA" description:"Generate JSON Schema for payloads and AsyncAPI document to directory"`
+	Chan             bool              `long:"chan" env:"CHAN" description:"Generate channel-based subscriptions (Chan, ChanBlocking) for events"`
+	Envelope         bool              `long:"envelope" env:"ENVELOPE" description:"Wrap mirrored and sunk events to envelope with metadata"`
+	JSON             bool              `long:"json" env:"JSON" description:"Generate JSON codec for universal emitter (implies --from-mirror)"`
+	Recorder         bool              `long:"recorder" env:"RECORDER" description:"Generate recorder of emitted events for event bus"`
+	Scopes           bool              `long:"scopes" env:"SCOPES" description:"Generate child (scoped) buses for event bus"`
+	Patterns         bool              `long:"patterns" env:"PATTERNS" description:"Generate SubscribePattern for event bus to subscribe by name glob or topic wildcards"`
+	Stats            bool              `long:"stats" env:"STATS" description:"Collect statistic of events (subscribers, emitted, latency, panics)"`
+	Expvar           bool              `long:"expvar" env:"EXPVAR" description:"Generate adapter to publish events statistic by expvar (implies --stats)"`
+	Tracing          bool              `long:"tracing" env:"TRACING" description:"Trace emitted events and handlers by pluggable tracer (implies --context)"`
+	Rate             bool              `long:"rate" env:"RATE" description:"Generate debounced, throttled and coalesced subscriptions for events"`
+	Validate         bool              `long:"validate" env:"VALIDATE" description:"Validate payloads by Validate() error method before dispatch"`
+	Args             struct {
 		Directories []string `help:"source directories (by default - current)"`
 	} `positional-args:"yes"`
 }
@@ -57,18 +72,32 @@ func main() {
 		out = jen.NewFile(config.Package)
 	}
 	ev := structview.EventGenerator{
-		WithContext:    config.Context,
-		WithMirror:     config.Mirror,
-		WithBus:        config.EventBus != "",
-		WithSink:       config.Sink,
-		BusName:        config.EventBus,
-		Private:        config.Private,
-		Hints:          config.Hint,
-		FromMirror:     config.FromMirror,
-		FromIgnoreCase: config.IgnoreCase,
-		Emitter:        config.Emitter,
-		Listener:       config.Listener,
-		PrivateEmit:    config.PrivateEmitter,
+		WithContext:          config.Context || config.Tracing,
+		WithChan:             config.Chan,
+		WithEnvelope:         config.Envelope,
+		WithJSON:             config.JSON,
+		WithRecorder:         config.Recorder,
+		WithScopes:           config.Scopes,
+		WithValidation:       config.Validate,
+		WithPatterns:         config.Patterns,
+		WithStats:            config.Stats || config.Expvar,
+		WithTracing:          config.Tracing,
+		WithRate:             config.Rate,
+		WithExpvar:           config.Expvar,
+		WithMirror:           config.Mirror || config.Mirrors,
+		WithMirrors:          config.Mirrors,
+		WithBus:              config.EventBus != "",
+		WithSink:             config.Sink,
+		BusName:              config.EventBus,
+		Private:              config.Private,
+		Hints:                config.Hint,
+		Interfaces:           config.Interface,
+		WithEmitterInterface: config.EmitterInterface,
+		FromMirror:           config.FromMirror || config.JSON,
+		FromIgnoreCase:       config.IgnoreCase,
+		Emitter:              config.Emitter,
+		Listener:             config.Listener,
+		PrivateEmit:          config.PrivateEmitter,
 	}
 	result, err := ev.Generate(config.Args.Directories...)
 	if err != nil {
@@ -93,4 +122,24 @@ func main() {
 			panic(err)
 		}
 	}
+	if config.Schema != "" {
+		title := config.EventBus
+		if title == "" {
+			title = config.Package
+		}
+		files, err := internal.GenerateSchema(title, result)
+		if err != nil {
+			panic(err)
+		}
+		err = os.MkdirAll(config.Schema, 0755)
+		if err != nil {
+			panic(err)
+		}
+		for name, data := range files {
+			err = ioutil.WriteFile(filepath.Join(config.Schema, name), data, 0755)
+			if err != nil {
+				panic(err)
+			}
+		}
+	}
 }
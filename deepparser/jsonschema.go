@@ -0,0 +1,105 @@
+package deepparser
+
+import (
+	"go/ast"
+	"strings"
+)
+
+type Schema map[string]interface{}
+
+type JSONSchema struct {
+	Typer
+	RefPrefix string // prefix for references to definitions (ex: #/definitions/)
+}
+
+func (jsg *JSONSchema) mapBase(typeName string) Schema {
+	switch typeName {
+	case "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+		"byte", "rune":
+		return Schema{"type": "integer"}
+	case "float32", "float64":
+		return Schema{"type": "number"}
+	case "string":
+		return Schema{"type": "string"}
+	case "bool":
+		return Schema{"type": "boolean"}
+	}
+	if jsg.find(typeName) != nil {
+		return Schema{"$ref": jsg.RefPrefix + typeName}
+	}
+	return Schema{}
+}
+
+func (jsg *JSONSchema) find(typeName string) *Definition {
+	for _, def := range jsg.Ordered {
+		if def.TypeName == typeName {
+			return def
+		}
+	}
+	return nil
+}
+
+func (jsg *JSONSchema) MapType(t ast.Expr) Schema {
+	switch v := t.(type) {
+	case *ast.Ident:
+		return jsg.mapBase(v.Name)
+	case *ast.SelectorExpr:
+		if pkg, ok := v.X.(*ast.Ident); ok && pkg.Name == "time" && v.Sel.Name == "Time" {
+			return Schema{"type": "string", "format": "date-time"}
+		}
+		return jsg.mapBase(v.Sel.Name)
+	case *ast.StarExpr:
+		return jsg.MapType(v.X)
+	case *ast.ArrayType:
+		if elt, ok := v.Elt.(*ast.Ident); ok && elt.Name == "byte" {
+			return Schema{"type": "string", "contentEncoding": "base64"}
+		}
+		return Schema{"type": "array", "items": jsg.MapType(v.Elt)}
+	case *ast.MapType:
+		return Schema{"type": "object", "additionalProperties": jsg.MapType(v.Value)}
+	}
+	return Schema{}
+}
+
+func (jsg *JSONSchema) MapField(st *StField) Schema {
+	schema := jsg.MapType(st.AST.Type)
+	if st.Comment != "" {
+		schema["description"] = strings.TrimSpace(st.Comment)
+	}
+	return schema
+}
+
+func (jsg *JSONSchema) MapDefinition(def *Definition) Schema {
+	if !def.IsStruct() {
+		return jsg.MapType(def.Type.Type)
+	}
+	properties := Schema{}
+	var required []string
+	for _, field := range def.StructFields() {
+		properties[field.Tag] = jsg.MapField(field)
+		if !field.Omitempty {
+			required = append(required, field.Tag)
+		}
+	}
+	schema := Schema{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func (jsg *JSONSchema) Definitions() Schema {
+	definitions := Schema{}
+	for _, def := range jsg.Ordered {
+		if def.Import.Path == "time" && def.TypeName == "Time" {
+			// mapped as string in date-time format
+			continue
+		}
+		definitions[def.TypeName] = jsg.MapDefinition(def)
+	}
+	return definitions
+}
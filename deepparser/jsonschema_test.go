@@ -0,0 +1,99 @@
+package deepparser
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+type schemaOrder struct {
+	ID       uint64           `json:"id"`
+	Price    float64          `json:"price"`
+	Paid     bool             `json:"paid"`
+	Note     string           `json:"note,omitempty"` // free text
+	Created  time.Time        `json:"created"`
+	Items    []schemaItem     `json:"items"`
+	Labels   map[string]int32 `json:"labels,omitempty"`
+	Owner    *schemaOwner     `json:"owner,omitempty"`
+	Data     []byte           `json:"data,omitempty"`
+	Interval interval         `json:"interval"`
+	Hook     integration      `json:"hook"`
+	Secret   string           `json:"-"`
+}
+
+type schemaItem struct {
+	SKU string `json:"sku"`
+}
+
+type schemaOwner struct {
+	Name string
+}
+
+// names look like builtin types but they are not
+type interval string
+
+type integration struct {
+	URL string `json:"url"`
+}
+
+type schemaItems []*schemaItem
+
+type schemaLabels map[string]floats
+
+type floats []float32
+
+func TestJSONSchema_MapDefinition(t *testing.T) {
+	cases := []struct {
+		typeName string
+		expected string
+	}{
+		{"schemaOrder", `{"properties":{` +
+			`"created":{"format":"date-time","type":"string"},` +
+			`"data":{"contentEncoding":"base64","type":"string"},` +
+			`"hook":{"$ref":"#/definitions/integration"},` +
+			`"id":{"type":"integer"},` +
+			`"interval":{"$ref":"#/definitions/interval"},` +
+			`"items":{"items":{"$ref":"#/definitions/schemaItem"},"type":"array"},` +
+			`"labels":{"additionalProperties":{"type":"integer"},"type":"object"},` +
+			`"note":{"description":"free text","type":"string"},` +
+			`"owner":{"$ref":"#/definitions/schemaOwner"},` +
+			`"paid":{"type":"boolean"},` +
+			`"price":{"type":"number"}},` +
+			`"required":["id","price","paid","created","items","interval","hook"],"type":"object"}`},
+		{"schemaOwner", `{"properties":{"Name":{"type":"string"}},"required":["Name"],"type":"object"}`},
+		{"interval", `{"type":"string"}`},
+		{"integration", `{"properties":{"url":{"type":"string"}},"required":["url"],"type":"object"}`},
+		{"schemaItems", `{"items":{"$ref":"#/definitions/schemaItem"},"type":"array"}`},
+		{"schemaLabels", `{"additionalProperties":{"$ref":"#/definitions/floats"},"type":"object"}`},
+		{"floats", `{"items":{"type":"number"},"type":"array"}`},
+	}
+	jsg := JSONSchema{RefPrefix: "#/definitions/"}
+	jsg.AddFromDir("schemaOrder", ".")
+	jsg.AddFromDir("schemaItems", ".")
+	jsg.AddFromDir("schemaLabels", ".")
+	jsg.AddFromDir("floats", ".")
+	for _, c := range cases {
+		def := jsg.find(c.typeName)
+		if def == nil {
+			t.Errorf("%s: definition not found", c.typeName)
+			continue
+		}
+		data, err := json.Marshal(jsg.MapDefinition(def))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != c.expected {
+			t.Errorf("%s:\n%s\n!=\n%s", c.typeName, data, c.expected)
+		}
+	}
+
+	definitions := jsg.Definitions()
+	if _, ok := definitions["Time"]; ok {
+		t.Error("time.Time should not be in definitions")
+	}
+	for _, c := range cases {
+		if _, ok := definitions[c.typeName]; !ok {
+			t.Error("definition missed", c.typeName)
+		}
+	}
+}
@@ -90,7 +90,7 @@ func findDefinitionFromAst(typeName, alias string, file *ast.File, fileDir strin
 	}
 
 	var fs token.FileSet
-	importFile, err := parser.ParseDir(&fs, importDef.Location, nil, parser.AllErrors)
+	importFile, err := parser.ParseDir(&fs, importDef.Location, nil, parser.AllErrors|parser.ParseComments)
 	if err != nil {
 		log.Println("failed parse", importDef.Location, ":", err)
 		return nil
@@ -128,7 +128,7 @@ type StField struct {
 	Omitempty bool
 }
 
-func (def *Definition) isStruct() bool {
+func (def *Definition) IsStruct() bool {
 	_, ok := def.Type.Type.(*ast.StructType)
 	return ok
 }
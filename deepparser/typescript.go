@@ -30,6 +30,9 @@ func (tsg *TypeScript) MapType(t ast.Expr) string {
 		return tsg.mapBase(v.Name)
 	}
 	if acc, ok := t.(*ast.SelectorExpr); ok {
+		if pkg, ok := acc.X.(*ast.Ident); ok && pkg.Name == "time" && acc.Sel.Name == "Time" {
+			return "string"
+		}
 		return acc.Sel.Name
 	}
 	if ptr, ok := t.(*ast.StarExpr); ok {
@@ -40,9 +43,17 @@ func (tsg *TypeScript) MapType(t ast.Expr) string {
 		return "Array<" + tsg.MapType(arr.Elt) + ">"
 	}
 
+	if mp, ok := t.(*ast.MapType); ok {
+		return "{ [key: string]: " + tsg.MapType(mp.Value) + " }"
+	}
+
 	return "any"
 }
 
+func (tsg *TypeScript) MapDefinition(def *Definition) string {
+	return tsg.MapType(def.Type.Type)
+}
+
 func (tsg *TypeScript) MapField(st *StField) string {
 	tp := tsg.MapType(st.AST.Type)
 	if st.Omitempty {
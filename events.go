@@ -7,23 +7,38 @@ import (
 	"go/parser"
 	"go/token"
 	"log"
+	"sort"
 	"strings"
 )
 
 type EventGenerator struct {
-	WithBus        bool
-	WithMirror     bool
-	WithSink       bool
-	WithContext    bool
-	FromMirror     bool
-	FromIgnoreCase bool
-	BusName        string
-	MirrorType     string
-	Private        bool
-	Emitter        string
-	Listener       string
-	PrivateEmit    bool
-	Hints          map[string]string // Event->Struct Name
+	WithBus              bool
+	WithMirror           bool
+	WithSink             bool
+	WithContext          bool
+	WithChan             bool
+	WithEnvelope         bool
+	WithJSON             bool
+	WithRecorder         bool
+	WithScopes           bool
+	WithValidation       bool
+	WithPatterns         bool
+	WithStats            bool
+	WithExpvar           bool // requires WithStats
+	WithTracing          bool // requires WithContext
+	WithEmitterInterface bool // requires Emitter
+	WithRate             bool
+	WithMirrors          bool // requires WithMirror and WithBus
+	FromMirror           bool
+	FromIgnoreCase       bool
+	BusName              string
+	MirrorType           string
+	Private              bool
+	Emitter              string
+	Listener             string
+	PrivateEmit          bool
+	Hints                map[string]string // Event->Struct Name
+	Interfaces           []string          // Interfaces which methods are events
 }
 
 type Event struct {
@@ -45,6 +60,13 @@ func (eg EventGenerator) Generate(directories ...string) (*EventGeneratorResult,
 	)
 
 	var usedEvents []Event
+	var hasReplies bool
+
+	var hints = make([]string, 0, len(eg.Hints))
+	for eventName := range eg.Hints {
+		hints = append(hints, eventName)
+	}
+	sort.Strings(hints)
 
 	code := jen.Empty()
 	for _, directory := range directories {
@@ -54,75 +76,119 @@ func (eg EventGenerator) Generate(directories ...string) (*EventGeneratorResult,
 			return nil, err
 		}
 		var (
-			name        string
+			file        *ast.File
+			spec        *ast.TypeSpec
 			comment     string
 			prevComment string
+			validators  map[string]bool
 		)
-		for _, def := range p {
+		if eg.WithValidation {
+			validators = findValidators(p)
+		}
+		add := func(info *Struct, eventName string) {
+			typeName := eventName
+			if eg.Private {
+				typeName = "event" + eventName
+			}
+			code.Add(eg.generateForType(info, eventName, typeName))
+			code.Add(jen.Line())
+			events = append(events, eventName)
+			types = append(types, typeName)
+			payloads = append(payloads, info)
+			if info.Reply != nil {
+				hasReplies = true
+			}
+
+			usedEvents = append(usedEvents, Event{
+				Name:     eventName,
+				TypeName: info.Struct,
+				Dir:      info.Dir,
+			})
+		}
+		register := func(definition *ast.StructType) {
+			name := spec.Name.Name
+			info, err := WrapStruct(directory, name, definition)
+			if err != nil {
+				log.Println(err)
+				return
+			}
+			info.Validator = validators[name]
+			var eventsToGenerate []string
+			var isRef []bool
+			var replies []*Struct
+			for _, eventName := range hints {
+				if name == eg.Hints[eventName] {
+					eventsToGenerate = append(eventsToGenerate, eventName)
+					isRef = append(isRef, false)
+					replies = append(replies, nil)
+				}
+			}
+			for _, line := range strings.Split(comment, "\n") {
+				line = strings.TrimSpace(line)
+				val, err := structtag.Parse(line)
+				if err != nil {
+					continue
+				}
+				if event, err := val.Get("event"); err == nil && event != nil {
+					eventsToGenerate = append(eventsToGenerate, event.Name)
+					isRef = append(isRef, event.HasOption("ref"))
+					replies = append(replies, replyType(event.Options, info))
+				}
+
+			}
+			for i, eventName := range eventsToGenerate {
+				cp := *info
+				if isRef[i] {
+					cp = cp.AsRef()
+				}
+				cp.Reply = replies[i]
+				add(&cp, eventName)
+			}
+
+			comment = ""
+		}
+		for _, def := range sortedFiles(p) {
 			ast.Inspect(def, func(node ast.Node) bool {
 				switch v := node.(type) {
+				case *ast.File:
+					file = v
 				case *ast.CommentGroup:
 					prevComment = v.Text()
 				case *ast.TypeSpec:
-					name = v.Name.Name
+					spec = v
 					comment = strings.TrimSpace(prevComment)
 					prevComment = ""
-				case *ast.StructType:
-					info, err := WrapStruct(directory, name, v)
-					if err != nil {
-						log.Println(err)
-						return true
-					}
-					var eventsToGenerate []string
-					var isRef []bool
-					for eventName, structName := range eg.Hints {
-						if name == structName {
-							eventsToGenerate = append(eventsToGenerate, eventName)
-							isRef = append(isRef, false)
+					if iface, ok := v.Type.(*ast.InterfaceType); ok && eg.isEventsInterface(v.Name.Name) {
+						for _, event := range eg.interfaceEvents(directory, file, iface) {
+							add(event.Payload, event.Name)
 						}
+						comment = ""
+					} else if _, isStruct := v.Type.(*ast.StructType); !isStruct {
+						// non-struct named types (ex: type IDs []int64)
+						register(nil)
 					}
-					for _, line := range strings.Split(comment, "\n") {
-						line = strings.TrimSpace(line)
-						val, err := structtag.Parse(line)
-						if err != nil {
-							continue
-						}
-						if event, err := val.Get("event"); err == nil && event != nil {
-							eventsToGenerate = append(eventsToGenerate, event.Name)
-							isRef = append(isRef, event.HasOption("ref"))
-						}
-
-					}
-					for i, eventName := range eventsToGenerate {
-						typeName := eventName
-						if eg.Private {
-							typeName = "event" + eventName
-						}
-						ref := isRef[i]
-						cp := *info
-						if ref {
-							cp = cp.AsRef()
-						}
-						code.Add(eg.generateForType(&cp, typeName, false))
-						code.Add(jen.Line())
-						events = append(events, eventName)
-						types = append(types, typeName)
-						payloads = append(payloads, &cp)
-
-						usedEvents = append(usedEvents, Event{
-							Name:     eventName,
-							TypeName: cp.Struct,
-							Dir:      cp.Dir,
-						})
+				case *ast.StructType:
+					if spec != nil && spec.Type == v {
+						register(v)
 					}
-
-					comment = ""
 				}
 				return true
 			})
 		}
 	}
 
+	if hasReplies {
+		code.Add(eg.generateRequestErrors())
+		code.Add(jen.Line())
+	}
+	if eg.WithStats {
+		code.Add(eg.generateStatsType())
+		code.Add(jen.Line())
+	}
+	if eg.tracing() {
+		code.Add(eg.generateTracer())
+		code.Add(jen.Line())
+	}
 	if eg.WithBus {
 		code.Add(eg.generateBus(eg.BusName, events, types))
 		code.Add(jen.Line())
@@ -131,18 +197,62 @@ func (eg EventGenerator) Generate(directories ...string) (*EventGeneratorResult,
 		code.Add(eg.generateMirrorConstructorForBus(eg.MirrorType, eg.BusName, events))
 		code.Add(jen.Line())
 	}
+	if eg.multiMirror() {
+		code.Add(eg.generateMirrors(eg.BusName, events))
+		code.Add(jen.Line())
+	}
 	if eg.WithSink && eg.WithBus {
 		code.Add(eg.generateSinkForBus(eg.BusName, events, payloads))
 		code.Add(jen.Line())
 	}
+	if eg.WithPatterns && eg.WithBus {
+		code.Add(eg.generatePatterns(eg.BusName, events, payloads))
+		code.Add(jen.Line())
+	}
 	if eg.FromMirror && eg.WithBus {
 		code.Add(eg.generateBusSource(eg.BusName, events, payloads))
 		code.Add(jen.Line())
 	}
+	if eg.FromMirror && eg.WithBus {
+		code.Add(eg.generateTryEmit(eg.BusName, events, payloads))
+		code.Add(jen.Line())
+	}
+	if eg.FromMirror && eg.WithBus && eg.WithJSON {
+		code.Add(eg.generateJSONCodec(eg.BusName, events, payloads))
+		code.Add(jen.Line())
+	}
+	if eg.FromMirror && eg.WithBus && eg.WithEnvelope {
+		code.Add(eg.generateEnvelopeSource(eg.BusName, events, payloads))
+		code.Add(jen.Line())
+	}
+	if eg.WithEnvelope {
+		code.Add(eg.generateEnvelope())
+		code.Add(jen.Line())
+	}
 	if eg.WithBus && eg.Emitter != "" {
 		code.Add(eg.generateEmitter(eg.BusName, events, types, payloads))
 		code.Add(jen.Line())
 	}
+	if eg.WithBus && eg.Emitter != "" && eg.WithEmitterInterface {
+		code.Add(eg.generateEmitterInterface(eg.BusName, events, payloads))
+		code.Add(jen.Line())
+	}
+	if eg.WithBus && eg.WithScopes {
+		code.Add(eg.generateScopes(eg.BusName, events))
+		code.Add(jen.Line())
+	}
+	if eg.WithBus && eg.WithStats {
+		code.Add(eg.generateBusStats(eg.BusName, events))
+		code.Add(jen.Line())
+	}
+	if eg.WithBus && eg.tracing() {
+		code.Add(eg.generateBusTracing(eg.BusName, events))
+		code.Add(jen.Line())
+	}
+	if eg.WithBus && eg.WithRecorder {
+		code.Add(eg.generateRecorder(eg.BusName, events, payloads))
+		code.Add(jen.Line())
+	}
 	if eg.WithBus && eg.Listener != "" {
 		code.Add(eg.generateListener(eg.BusName, events, payloads))
 		code.Add(jen.Line())
@@ -153,6 +263,53 @@ func (eg EventGenerator) Generate(directories ...string) (*EventGeneratorResult,
 	}, nil
 }
 
+// sortedFiles returns files of all packages ordered by file name to keep generated code stable
+func sortedFiles(packages map[string]*ast.Package) []*ast.File {
+	var names []string
+	var files = make(map[string]*ast.File)
+	for _, pkg := range packages {
+		for name, file := range pkg.Files {
+			names = append(names, name)
+			files[name] = file
+		}
+	}
+	sort.Strings(names)
+	var ans = make([]*ast.File, 0, len(names))
+	for _, name := range names {
+		ans = append(ans, files[name])
+	}
+	return ans
+}
+
+// findValidators finds types with method Validate() error
+func findValidators(packages map[string]*ast.Package) map[string]bool {
+	var validators = make(map[string]bool)
+	for _, pkg := range packages {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				fn, ok := decl.(*ast.FuncDecl)
+				if !ok || fn.Recv == nil || len(fn.Recv.List) != 1 || fn.Name.Name != "Validate" {
+					continue
+				}
+				if fn.Type.Params.NumFields() != 0 || fn.Type.Results.NumFields() != 1 {
+					continue
+				}
+				if res, ok := fn.Type.Results.List[0].Type.(*ast.Ident); !ok || res.Name != "error" {
+					continue
+				}
+				recv := fn.Recv.List[0].Type
+				if star, ok := recv.(*ast.StarExpr); ok {
+					recv = star.X
+				}
+				if ident, ok := recv.(*ast.Ident); ok {
+					validators[ident.Name] = true
+				}
+			}
+		}
+	}
+	return validators
+}
+
 func (eg EventGenerator) emitFunc() string {
 	if eg.PrivateEmit {
 		return "emit"
@@ -160,51 +317,336 @@ func (eg EventGenerator) emitFunc() string {
 	return "Emit"
 }
 
-func (eg EventGenerator) generateForType(info *Struct, eventName string, flat bool) jen.Code {
+func (eg EventGenerator) tryEmitFunc() string {
+	if eg.PrivateEmit {
+		return "tryEmit"
+	}
+	return "TryEmit"
+}
+
+func (eg EventGenerator) generateForType(info *Struct, eventName, impl string) jen.Code {
 	handlerType := jen.Func().Params(info.Qual())
 	if eg.WithContext {
 		handlerType = jen.Func().Params(jen.Qual("context", "Context"), info.Qual())
 	}
-	impl := eventName
-	mirrorFunc := jen.Func().Params(jen.Id("eventName").String(), jen.Id("payload").Interface())
+	mirrorFunc := eg.mirrorFunc()
 	code := jen.Type().Id(impl).StructFunc(func(group *jen.Group) {
 		group.Id("lock").Qual("sync", "RWMutex")
 		group.Id("handlers").Index().Add(handlerType)
-		if eg.WithMirror {
+		if eg.multiMirror() {
+			group.Id("mirrors").Qual("sync/atomic", "Value")
+		} else if eg.WithMirror {
 			group.Id("mirror").Add(mirrorFunc)
 		}
+		if eg.trackHandlers() {
+			group.Id("ids").Index().Uint64()
+			group.Id("lastID").Uint64()
+		}
+		if eg.envelopeSinks() {
+			group.Id("sinks").Index().Func().Params(jen.Id("envelope").Id(eg.globalName("Envelope")))
+			group.Id("sinkIDs").Index().Uint64()
+			group.Id("lastSinkID").Uint64()
+		}
+		if eg.WithScopes {
+			group.Id("parent").Op("*").Id(impl)
+			group.Id("detach").Index().Func().Params()
+		}
+		if eg.tracing() {
+			group.Id("tracer").Id(eg.globalName("Tracer"))
+		}
+		if eg.WithStats {
+			group.Id("statsLock").Qual("sync", "Mutex")
+			group.Id("stats").Id(eg.globalName("EventStats"))
+		}
+		if info.Reply != nil {
+			group.Id("responder").Add(eg.responderFunc(info))
+			if eg.WithScopes {
+				group.Id("responderID").Uint64()
+			}
+		}
 	}).Line()
 	code = code.Func().Params(jen.Id("ev").Op("*").Id(impl)).Id("Subscribe").Params(jen.Id("handler").Add(handlerType)).BlockFunc(func(group *jen.Group) {
+		if eg.trackHandlers() {
+			group.Id("ev").Dot("subscribe").Call(jen.Id("handler"))
+			return
+		}
 		group.Id("ev").Dot("lock").Dot("Lock").Call()
 		group.Id("ev").Dot("handlers").Op("=").Append(jen.Id("ev").Dot("handlers"), jen.Id("handler"))
 		group.Id("ev").Dot("lock").Dot("Unlock").Call()
 	}).Line()
+	if eg.trackHandlers() {
+		code = code.Add(eg.generateUnsubscribe(impl, handlerType))
+	}
+	if eg.WithChan {
+		code = code.Add(eg.generateChan(info, impl))
+	}
+	if eg.WithScopes {
+		code = code.Add(eg.generateScopeClose(impl))
+	}
+	if info.Reply != nil {
+		code = code.Add(eg.generateRequest(info, eventName, impl))
+	}
+	if eg.WithStats {
+		code = code.Add(eg.generateStats(info, impl, handlerType))
+	}
+	if eg.WithRate {
+		code = code.Add(eg.generateRate(info, impl, handlerType))
+	}
+	if eg.tracing() {
+		code = code.Add(eg.generateTracing(info, eventName, impl, handlerType))
+	}
+
+	if eg.envelopeSinks() {
+		code = code.Add(eg.generateEnvelopeSinks(impl))
+	}
+	if eg.WithEnvelope {
+		return code.Add(eg.generateEnvelopeEmit(info, eventName, impl))
+	}
 
 	code = code.Func().Params(jen.Id("ev").Op("*").Id(impl)).Id(eg.emitFunc()).ParamsFunc(func(params *jen.Group) {
 		if eg.WithContext {
 			params.Id("ctx").Qual("context", "Context")
 		}
-		if len(info.Definition.Fields.List) != 0 {
+		if !info.Empty() {
 			params.Id("payload").Add(info.Qual())
 		}
-	}).BlockFunc(func(group *jen.Group) {
-		if len(info.Definition.Fields.List) == 0 {
-			group.Id("payload").Op(":=").Add(info.Qual()).Values()
-		}
-		group.Id("ev").Dot("lock").Dot("RLock").Call()
-		group.For(jen.List(jen.Id("_"), jen.Id("handler")).Op(":=").Range().Id("ev").Dot("handlers")).BlockFunc(func(iter *jen.Group) {
-			iter.Id("handler").CallFunc(func(calle *jen.Group) {
+	}).Add(eg.emitResult(info)).BlockFunc(func(group *jen.Group) {
+		if eg.WithScopes {
+			group.If(jen.Id("ev").Dot("parent").Op("!=").Nil()).Add(eg.returnEmit(info, jen.Id("ev").Dot("parent").Dot(eg.emitFunc()).CallFunc(func(call *jen.Group) {
 				if eg.WithContext {
-					calle.Id("ctx")
+					call.Id("ctx")
 				}
-				calle.Id("payload")
-			})
+				if !info.Empty() {
+					call.Id("payload")
+				}
+			})))
+		}
+		if info.Empty() {
+			group.Id("payload").Op(":=").Add(info.Qual()).Values()
+		}
+		eg.validate(group, info, eventName)
+		eg.dispatch(group, eventName, jen.Lit(eventName), jen.Id("payload"))
+		if eg.emitsError(info) {
+			group.Return().Nil()
+		}
+	}).Line()
+	return code
+}
+
+// emitsError checks that emitter of event returns error
+func (eg EventGenerator) emitsError(info *Struct) bool {
+	return eg.WithValidation && info.Validator
+}
+
+func (eg EventGenerator) emitResult(info *Struct) jen.Code {
+	if eg.emitsError(info) {
+		return jen.Error()
+	}
+	return jen.Empty()
+}
+
+// returnEmit generates block that returns result of emit call (if any)
+func (eg EventGenerator) returnEmit(info *Struct, emit jen.Code) jen.Code {
+	if eg.emitsError(info) {
+		return jen.Block(jen.Return(emit))
+	}
+	return jen.Block(emit, jen.Return())
+}
+
+// validate generates check of payload before dispatch
+func (eg EventGenerator) validate(group *jen.Group, info *Struct, eventName string) {
+	if !eg.emitsError(info) {
+		return
+	}
+	group.If(jen.Err().Op(":=").Id("payload").Dot("Validate").Call(), jen.Err().Op("!=").Nil()).Block(
+		jen.Return().Qual("fmt", "Errorf").Call(jen.Lit("invalid payload of event "+eventName+": %w"), jen.Err()),
+	)
+}
+
+// dispatch generates invocation of all handlers and mirror
+func (eg EventGenerator) dispatch(group *jen.Group, eventName string, mirrorArgs ...jen.Code) {
+	if eg.WithStats {
+		group.Id("ev").Dot("statsLock").Dot("Lock").Call()
+		group.Id("ev").Dot("stats").Dot("Emitted").Op("++")
+		group.Id("ev").Dot("statsLock").Dot("Unlock").Call()
+	}
+	group.Id("ev").Dot("lock").Dot("RLock").Call()
+	handlerIndex := jen.Id("_")
+	if eg.tracing() {
+		group.Id("tracer").Op(":=").Id("ev").Dot("tracer")
+		group.If(jen.Id("tracer").Op("!=").Nil()).Block(
+			jen.Var().Id("span").Id(eg.globalName("Span")),
+			jen.List(jen.Id("ctx"), jen.Id("span")).Op("=").Id("tracer").Dot("Start").Call(jen.Id("ctx"), jen.Lit("emit "+eventName), eg.spanAttributes(eventName)),
+			jen.Defer().Id("span").Dot("End").Call(),
+		)
+		handlerIndex = jen.Id("i")
+	}
+	group.For(jen.List(handlerIndex, jen.Id("handler")).Op(":=").Range().Id("ev").Dot("handlers")).BlockFunc(func(iter *jen.Group) {
+		if eg.tracing() {
+			iter.If(jen.Id("tracer").Op("!=").Nil()).Block(
+				jen.Id("ev").Dot("traceHandler").CallFunc(func(call *jen.Group) {
+					call.Id("ctx")
+					call.Id("tracer")
+					call.Id("i")
+					call.Id("handler")
+					call.Id("payload")
+					if eg.WithEnvelope {
+						call.Id("envelope")
+					}
+				}),
+				jen.Continue(),
+			)
+		}
+		iter.Add(eg.invokeHandler())
+	})
+	group.Id("ev").Dot("lock").Dot("RUnlock").Call()
+	if eg.multiMirror() {
+		group.Add(eg.dispatchMirrors(mirrorArgs...))
+	} else if eg.WithMirror {
+		group.If(jen.Id("mirror").Op(":=").Id("ev").Dot("mirror"), jen.Id("mirror").Op("!=").Nil()).Block(
+			jen.Id("mirror").Call(mirrorArgs...),
+		)
+	}
+}
+
+// invokeHandler generates invocation of single handler (with statistic if needed)
+func (eg EventGenerator) invokeHandler() jen.Code {
+	if eg.WithStats {
+		return jen.Id("ev").Dot("invoke").CallFunc(func(calle *jen.Group) {
+			if eg.WithContext {
+				calle.Id("ctx")
+			}
+			calle.Id("handler")
+			calle.Id("payload")
+		})
+	}
+	return jen.Id("handler").CallFunc(func(calle *jen.Group) {
+		if eg.WithContext {
+			calle.Id("ctx")
+		}
+		calle.Id("payload")
+	})
+}
+
+// globalName prefixes package-level identifier by bus name (like <Bus>Recorder) to avoid collisions with payload types
+// and other buses in the same package. Unexported identifiers stay unexported. Without bus name is used as is.
+func (eg EventGenerator) globalName(name string) string {
+	if eg.BusName == "" {
+		return name
+	}
+	if ast.IsExported(name) {
+		return eg.BusName + name
+	}
+	return strings.ToLower(eg.BusName[:1]) + eg.BusName[1:] + strings.ToUpper(name[:1]) + name[1:]
+}
+
+// errorName is the same as globalName for sentinel errors, but keeps Err prefix first (ErrEventsUnknownEvent)
+func (eg EventGenerator) errorName(name string) string {
+	if eg.BusName == "" {
+		return name
+	}
+	return "Err" + eg.BusName + strings.TrimPrefix(name, "Err")
+}
+
+// trackHandlers enables unsubscribe by ID (required by features that detach handlers)
+func (eg EventGenerator) trackHandlers() bool {
+	return eg.WithChan || eg.WithScopes || eg.WithRate || eg.WithPatterns
+}
+
+func (eg EventGenerator) generateUnsubscribe(impl string, handlerType jen.Code) jen.Code {
+	code := jen.Func().Params(jen.Id("ev").Op("*").Id(impl)).Id("subscribe").Params(jen.Id("handler").Add(handlerType)).Params(jen.Id("unsubscribe").Func().Params()).BlockFunc(func(group *jen.Group) {
+		if eg.WithScopes {
+			group.If(jen.Id("ev").Dot("parent").Op("!=").Nil()).Block(
+				jen.Id("unsubscribe").Op("=").Id("ev").Dot("parent").Dot("subscribe").Call(jen.Id("handler")),
+				jen.Id("ev").Dot("lock").Dot("Lock").Call(),
+				jen.Id("ev").Dot("detach").Op("=").Append(jen.Id("ev").Dot("detach"), jen.Id("unsubscribe")),
+				jen.Id("ev").Dot("lock").Dot("Unlock").Call(),
+				jen.Return(jen.Id("unsubscribe")),
+			)
+		}
+		group.Id("ev").Dot("lock").Dot("Lock").Call()
+		group.Id("ev").Dot("lastID").Op("++")
+		group.Id("id").Op(":=").Id("ev").Dot("lastID")
+		group.Id("ev").Dot("handlers").Op("=").Append(jen.Id("ev").Dot("handlers"), jen.Id("handler"))
+		group.Id("ev").Dot("ids").Op("=").Append(jen.Id("ev").Dot("ids"), jen.Id("id"))
+		group.Id("ev").Dot("lock").Dot("Unlock").Call()
+		group.Return().Func().Params().BlockFunc(func(fn *jen.Group) {
+			fn.Id("ev").Dot("unsubscribe").Call(jen.Id("id"))
 		})
-		group.Id("ev").Dot("lock").Dot("RUnlock").Call()
-		if eg.WithMirror {
-			group.If(jen.Id("mirror").Op(":=").Id("ev").Dot("mirror"), jen.Id("mirror").Op("!=").Nil()).BlockFunc(func(mirror *jen.Group) {
-				mirror.Id("mirror").Call(jen.Lit(eventName), jen.Id("payload"))
+	}).Line()
+	code = code.Func().Params(jen.Id("ev").Op("*").Id(impl)).Id("unsubscribe").Params(jen.Id("id").Uint64()).BlockFunc(func(group *jen.Group) {
+		group.Id("ev").Dot("lock").Dot("Lock").Call()
+		group.Defer().Id("ev").Dot("lock").Dot("Unlock").Call()
+		group.For(jen.List(jen.Id("i"), jen.Id("handlerID")).Op(":=").Range().Id("ev").Dot("ids")).BlockFunc(func(iter *jen.Group) {
+			iter.If(jen.Id("handlerID").Op("!=").Id("id")).Block(jen.Continue())
+			iter.Id("ev").Dot("handlers").Op("=").Append(jen.Id("ev").Dot("handlers").Index(jen.Empty(), jen.Id("i")), jen.Id("ev").Dot("handlers").Index(jen.Id("i").Op("+").Lit(1), jen.Empty()).Op("..."))
+			iter.Id("ev").Dot("ids").Op("=").Append(jen.Id("ev").Dot("ids").Index(jen.Empty(), jen.Id("i")), jen.Id("ev").Dot("ids").Index(jen.Id("i").Op("+").Lit(1), jen.Empty()).Op("..."))
+			iter.Return()
+		})
+	}).Line()
+	return code
+}
+
+func (eg EventGenerator) generateChan(info *Struct, impl string) jen.Code {
+	code := jen.Func().Params(jen.Id("ev").Op("*").Id(impl)).Id("Chan").Params(jen.Id("ctx").Qual("context", "Context"), jen.Id("buffer").Int()).Op("<-").Chan().Add(info.Qual()).BlockFunc(func(group *jen.Group) {
+		group.Return().Id("ev").Dot("subscribeChan").Call(jen.Id("ctx"), jen.Id("buffer"), jen.False())
+	}).Line()
+	code = code.Func().Params(jen.Id("ev").Op("*").Id(impl)).Id("ChanBlocking").Params(jen.Id("ctx").Qual("context", "Context"), jen.Id("buffer").Int()).Op("<-").Chan().Add(info.Qual()).BlockFunc(func(group *jen.Group) {
+		group.Return().Id("ev").Dot("subscribeChan").Call(jen.Id("ctx"), jen.Id("buffer"), jen.True())
+	}).Line()
+	code = code.Func().Params(jen.Id("ev").Op("*").Id(impl)).Id("subscribeChan").Params(jen.Id("ctx").Qual("context", "Context"), jen.Id("buffer").Int(), jen.Id("block").Bool()).Op("<-").Chan().Add(info.Qual()).BlockFunc(func(group *jen.Group) {
+		group.Id("ch").Op(":=").Make(jen.Chan().Add(info.Qual()), jen.Id("buffer"))
+		group.Id("unsubscribe").Op(":=").Id("ev").Dot("subscribe").Call(jen.Func().ParamsFunc(func(params *jen.Group) {
+			if eg.WithContext {
+				params.Id("_").Qual("context", "Context")
+			}
+			params.Id("payload").Add(info.Qual())
+		}).BlockFunc(func(handler *jen.Group) {
+			handler.If(jen.Id("block")).BlockFunc(func(blocking *jen.Group) {
+				blocking.Select().BlockFunc(func(sel *jen.Group) {
+					sel.Case(jen.Id("ch").Op("<-").Id("payload"))
+					sel.Case(jen.Op("<-").Id("ctx").Dot("Done").Call())
+				})
+				blocking.Return()
 			})
+			handler.Select().BlockFunc(func(sel *jen.Group) {
+				sel.Case(jen.Id("ch").Op("<-").Id("payload"))
+				sel.Default()
+			})
+		}))
+		group.Go().Func().Params().BlockFunc(func(detach *jen.Group) {
+			detach.Op("<-").Id("ctx").Dot("Done").Call()
+			detach.Id("unsubscribe").Call()
+			detach.Close(jen.Id("ch"))
+		}).Call()
+		group.Return().Id("ch")
+	}).Line()
+	return code
+}
+
+func (eg EventGenerator) generateScopeClose(impl string) jen.Code {
+	return jen.Func().Params(jen.Id("ev").Op("*").Id(impl)).Id("close").Params().Block(
+		jen.Id("ev").Dot("lock").Dot("Lock").Call(),
+		jen.Id("detach").Op(":=").Id("ev").Dot("detach"),
+		jen.Id("ev").Dot("detach").Op("=").Nil(),
+		jen.Id("ev").Dot("lock").Dot("Unlock").Call(),
+		jen.For(jen.List(jen.Id("_"), jen.Id("unsubscribe")).Op(":=").Range().Id("detach")).Block(
+			jen.Id("unsubscribe").Call(),
+		),
+	).Line()
+}
+
+func (eg EventGenerator) generateScopes(eventBus string, events []string) jen.Code {
+	code := jen.Func().Params(jen.Id("bus").Op("*").Id(eventBus)).Id("Child").Params().Op("*").Id(eventBus).BlockFunc(func(group *jen.Group) {
+		group.Var().Id("child").Id(eventBus)
+		for _, eventName := range events {
+			group.Id("child").Dot(eventName).Dot("parent").Op("=").Op("&").Id("bus").Dot(eventName)
+		}
+		group.Return().Op("&").Id("child")
+	}).Line()
+	code = code.Func().Params(jen.Id("bus").Op("*").Id(eventBus)).Id("Close").Params().BlockFunc(func(group *jen.Group) {
+		for _, eventName := range events {
+			group.Id("bus").Dot(eventName).Dot("close").Call()
 		}
 	}).Line()
 	return code
@@ -215,6 +657,9 @@ func (eg EventGenerator) generateBus(typeName string, events, types []string) je
 		for i, event := range events {
 			group.Id(event).Id(types[i])
 		}
+		if eg.multiMirror() {
+			eg.busMirrorFields(group)
+		}
 	})
 }
 
@@ -280,7 +725,7 @@ func (eg EventGenerator) generateBusSource(eventBus string, events []string, typ
 				for i, eventName := range events {
 					eventType := types[i]
 					sw.Case(jen.Lit(strings.ToUpper(eventName))).BlockFunc(func(evGroup *jen.Group) {
-						evGroup.Return().Op("&").Add(eventType.Qual()).Values()
+						evGroup.Return().New(eventType.AsValue().Qual())
 					})
 				}
 			})
@@ -290,7 +735,7 @@ func (eg EventGenerator) generateBusSource(eventBus string, events []string, typ
 				for i, eventName := range events {
 					eventType := types[i]
 					sw.Case(jen.Lit(eventName)).BlockFunc(func(evGroup *jen.Group) {
-						evGroup.Return().Op("&").Add(eventType.Qual()).Values()
+						evGroup.Return().New(eventType.AsValue().Qual())
 					})
 				}
 			})
@@ -300,10 +745,97 @@ func (eg EventGenerator) generateBusSource(eventBus string, events []string, typ
 	return code
 }
 
+func (eg EventGenerator) mirrorFunc() *jen.Statement {
+	if eg.WithEnvelope {
+		return jen.Func().Params(jen.Id("envelope").Id(eg.globalName("Envelope")))
+	}
+	return jen.Func().Params(jen.Id("eventName").String(), jen.Id("payload").Interface())
+}
+
+// generateTryEmit generates universal emitter that reports unknown events and invalid payloads as errors
+// as well as list of known events.
+func (eg EventGenerator) generateTryEmit(eventBus string, events []string, types []*Struct) jen.Code {
+	code := jen.Var().Defs(
+		jen.Id(eg.errorName("ErrUnknownEvent")).Op("=").Qual("errors", "New").Call(jen.Lit("unknown event")),
+		jen.Id(eg.errorName("ErrPayloadType")).Op("=").Qual("errors", "New").Call(jen.Lit("incorrect payload type")),
+	).Line()
+
+	code = code.Func().Params(jen.Id("ev").Op("*").Id(eventBus)).Id(eg.tryEmitFunc()).ParamsFunc(func(params *jen.Group) {
+		if eg.WithContext {
+			params.Id("ctx").Qual("context", "Context")
+		}
+		params.Id("eventName").String()
+		params.Id("payload").Interface()
+	}).Error().BlockFunc(func(group *jen.Group) {
+		emit := func(eventName string, payload jen.Code) jen.Code {
+			return jen.Id("ev").Dot(eventName).Dot(eg.emitFunc()).CallFunc(func(call *jen.Group) {
+				if eg.WithContext {
+					call.Id("ctx")
+				}
+				call.Add(payload)
+			})
+		}
+		group.Add(eg.eventSwitch(jen.Id("eventName"), events, func(i int, evGroup *jen.Group) {
+			eventType := types[i]
+			eventName := events[i]
+			returnEmit := func(payload jen.Code) jen.Code {
+				if eg.emitsError(eventType) {
+					return jen.Return(emit(eventName, payload))
+				}
+				return jen.Add(emit(eventName, payload)).Line().Return().Nil()
+			}
+			if eventType.Empty() {
+				evGroup.Add(returnEmit(jen.Empty()))
+				return
+			}
+			evGroup.If(jen.List(jen.Id("obj"), jen.Id("ok")).Op(":=").Id("payload").Op(".").Parens(eventType.Qual()), jen.Id("ok")).Block(
+				returnEmit(jen.Id("obj")),
+			)
+			evGroup.If(jen.List(jen.Id("obj"), jen.Id("ok")).Op(":=").Id("payload").Op(".").Parens(jen.Op("*").Add(eventType.Qual())), jen.Id("ok").Op("&&").Id("obj").Op("!=").Nil()).Block(
+				returnEmit(jen.Op("*").Id("obj")),
+			)
+			evGroup.Return().Qual("fmt", "Errorf").Call(jen.Lit("%w %T for event %s"), jen.Id(eg.errorName("ErrPayloadType")), jen.Id("payload"), jen.Id("eventName"))
+		}))
+		group.Return().Qual("fmt", "Errorf").Call(jen.Lit("%w %q"), jen.Id(eg.errorName("ErrUnknownEvent")), jen.Id("eventName"))
+	}).Line()
+
+	code = code.Func().Params(jen.Id("ev").Op("*").Id(eventBus)).Id("EventNames").Params().Index().String().Block(
+		jen.Return().Index().String().ValuesFunc(func(names *jen.Group) {
+			for _, eventName := range events {
+				names.Lit(eventName)
+			}
+		}),
+	).Line()
+	return code
+}
+
+// eventSwitch generates switch by event name (case-insensitive if required) with case per event.
+func (eg EventGenerator) eventSwitch(eventName jen.Code, events []string, caseFunc func(i int, group *jen.Group)) jen.Code {
+	if eg.FromIgnoreCase {
+		eventName = jen.Qual("strings", "ToUpper").Call(eventName)
+	}
+	return jen.Switch(eventName).BlockFunc(func(sw *jen.Group) {
+		for i, name := range events {
+			label := name
+			if eg.FromIgnoreCase {
+				label = strings.ToUpper(name)
+			}
+			sw.Case(jen.Lit(label)).BlockFunc(func(group *jen.Group) {
+				caseFunc(i, group)
+			})
+		}
+	})
+}
+
 func (eg EventGenerator) generateMirrorConstructorForBus(emitterType, eventBus string, events []string) jen.Code {
-	mirrorFunc := jen.Func().Params(jen.Id("eventName").String(), jen.Id("payload").Interface())
+	mirrorFunc := eg.mirrorFunc()
 	return jen.Func().Id(eventBus + "WithMirror").Params(jen.Id("mirror").Add(mirrorFunc)).Op("*").Id(eventBus).BlockFunc(func(group *jen.Group) {
 		group.Var().Id("bus").Id(eventBus)
+		if eg.multiMirror() {
+			group.Id("bus").Dot("AddMirror").Call(jen.Id("mirror"))
+			group.Return().Op("&").Id("bus")
+			return
+		}
 		for _, eventName := range events {
 			group.Id("bus").Dot(eventName).Dot("mirror").Op("=").Id("mirror")
 		}
@@ -312,32 +844,52 @@ func (eg EventGenerator) generateMirrorConstructorForBus(emitterType, eventBus s
 }
 
 func (eg EventGenerator) generateSinkForBus(eventBus string, events []string, types []*Struct) jen.Code {
-	mirrorFunc := jen.Func().ParamsFunc(func(group *jen.Group) {
+	return jen.Func().Params(jen.Id("bus").Op("*").Id(eventBus)).Id("Sink").Params(jen.Id("sink").Add(eg.sinkFunc())).Op("*").Id(eventBus).BlockFunc(func(group *jen.Group) {
+		for i, eventName := range events {
+			if eg.envelopeSinks() {
+				group.Id("bus").Dot(eventName).Dot("sink").Call(jen.Id("sink"))
+				continue
+			}
+			group.Id("bus").Dot(eventName).Dot("Subscribe").Call(eg.sinkHandler(eventName, types[i]))
+		}
+		group.Return().Id("bus")
+	})
+}
+
+// sinkFunc is a type of universal handler for all events
+func (eg EventGenerator) sinkFunc() jen.Code {
+	return jen.Func().ParamsFunc(func(group *jen.Group) {
 		if eg.WithContext {
 			group.Id("ctx").Qual("context", "Context")
 		}
+		if eg.WithEnvelope {
+			group.Id("envelope").Id(eg.globalName("Envelope"))
+			return
+		}
 		group.Id("eventName").String()
 		group.Id("payload").Interface()
 	})
-	return jen.Func().Params(jen.Id("bus").Op("*").Id(eventBus)).Id("Sink").Params(jen.Id("sink").Add(mirrorFunc)).Op("*").Id(eventBus).BlockFunc(func(group *jen.Group) {
-		for i, eventName := range events {
-			inType := types[i]
-			group.Id("bus").Dot(eventName).Dot("Subscribe").Call(jen.Func().ParamsFunc(func(params *jen.Group) {
-				if eg.WithContext {
-					params.Id("ctx").Qual("context", "Context")
-				}
-				params.Id("payload").Add(inType.Qual())
-			}).BlockFunc(func(closure *jen.Group) {
-				closure.Id("sink").CallFunc(func(calle *jen.Group) {
-					if eg.WithContext {
-						calle.Id("ctx")
-					}
-					calle.Lit(eventName)
-					calle.Id("payload")
-				})
-			}))
+}
+
+// sinkHandler generates typed event handler that forwards payload to the universal handler (sink)
+func (eg EventGenerator) sinkHandler(eventName string, inType *Struct) jen.Code {
+	return jen.Func().ParamsFunc(func(params *jen.Group) {
+		if eg.WithContext {
+			params.Id("ctx").Qual("context", "Context")
 		}
-		group.Return().Id("bus")
+		params.Id("payload").Add(inType.Qual())
+	}).BlockFunc(func(closure *jen.Group) {
+		if eg.WithEnvelope {
+			closure.Add(eg.sinkEnvelope(eventName))
+			return
+		}
+		closure.Id("sink").CallFunc(func(calle *jen.Group) {
+			if eg.WithContext {
+				calle.Id("ctx")
+			}
+			calle.Lit(eventName)
+			calle.Id("payload")
+		})
 	})
 }
 
@@ -355,17 +907,10 @@ func (eg EventGenerator) generateEmitter(eventBus string, events []string, etype
 
 	for i, event := range events {
 		eventType := types[i]
-		var hasArgs = len(eventType.Definition.Fields.List) > 0
+		var hasArgs = !eventType.Empty()
 
-		empty.Func().Params(jen.Id("emitter").Op("*").Id(emitter)).Id(event).ParamsFunc(func(params *jen.Group) {
-			if eg.WithContext {
-				params.Id("ctx").Qual("context", "Context")
-			}
-			if hasArgs {
-				params.Id("payload").Add(eventType.Qual())
-			}
-		}).BlockFunc(func(group *jen.Group) {
-			group.Id("emitter").Dot("events").Dot(event).Dot(eg.emitFunc()).CallFunc(func(call *jen.Group) {
+		eg.emitterMethod(empty.Func().Params(jen.Id("emitter").Op("*").Id(emitter)).Id(event), eventType).BlockFunc(func(group *jen.Group) {
+			emit := jen.Id("emitter").Dot("events").Dot(event).Dot(eg.emitFunc()).CallFunc(func(call *jen.Group) {
 				if eg.WithContext {
 					call.Id("ctx")
 				}
@@ -373,6 +918,11 @@ func (eg EventGenerator) generateEmitter(eventBus string, events []string, etype
 					call.Id("payload")
 				}
 			})
+			if eg.emitsError(eventType) {
+				group.Return(emit)
+			} else {
+				group.Add(emit)
+			}
 		}).Line()
 	}
 	return empty
@@ -0,0 +1,238 @@
+package structview
+
+import (
+	"github.com/dave/jennifer/jen"
+)
+
+// generateEnvelope generates Envelope type that carries event metadata together with payload and helpers
+// to build it. In context mode metadata also propagated through context.Context.
+func (eg EventGenerator) generateEnvelope() jen.Code {
+	code := jen.Type().Id(eg.globalName("Envelope")).Struct(
+		jen.Id("ID").String().Tag(map[string]string{"json": "id"}),
+		jen.Id("Event").String().Tag(map[string]string{"json": "event"}),
+		jen.Id("Timestamp").Qual("time", "Time").Tag(map[string]string{"json": "timestamp"}),
+		jen.Id("Source").String().Tag(map[string]string{"json": "source,omitempty"}),
+		jen.Id("CorrelationID").String().Tag(map[string]string{"json": "correlation_id,omitempty"}),
+		jen.Id("CausationID").String().Tag(map[string]string{"json": "causation_id,omitempty"}),
+		jen.Id("Payload").Interface().Tag(map[string]string{"json": "payload"}),
+	).Line()
+
+	code = code.Func().Id(eg.globalName("newEventID")).Params().String().BlockFunc(func(group *jen.Group) {
+		group.Var().Id("id").Index(jen.Lit(16)).Byte()
+		group.List(jen.Id("_"), jen.Id("_")).Op("=").Qual("crypto/rand", "Read").Call(jen.Id("id").Index(jen.Empty(), jen.Empty()))
+		group.Return().Qual("encoding/hex", "EncodeToString").Call(jen.Id("id").Index(jen.Empty(), jen.Empty()))
+	}).Line()
+
+	code = code.Func().Id(eg.globalName("newEnvelope")).ParamsFunc(func(params *jen.Group) {
+		if eg.WithContext {
+			params.Id("ctx").Qual("context", "Context")
+		}
+		params.Id("eventName").String()
+		params.Id("payload").Interface()
+	}).Id(eg.globalName("Envelope")).BlockFunc(func(group *jen.Group) {
+		group.Id("envelope").Op(":=").Id(eg.globalName("Envelope")).Values(jen.Dict{
+			jen.Id("ID"):        jen.Id(eg.globalName("newEventID")).Call(),
+			jen.Id("Event"):     jen.Id("eventName"),
+			jen.Id("Timestamp"): jen.Qual("time", "Now").Call(),
+			jen.Id("Payload"):   jen.Id("payload"),
+		})
+		if !eg.WithContext {
+			group.Return().Id("envelope")
+			return
+		}
+		group.If(jen.List(jen.Id("cause"), jen.Id("ok")).Op(":=").Id(eg.globalName("EnvelopeFromContext")).Call(jen.Id("ctx")), jen.Id("ok")).BlockFunc(func(caused *jen.Group) {
+			caused.Id("envelope").Dot("Source").Op("=").Id("cause").Dot("Source")
+			caused.Id("envelope").Dot("CausationID").Op("=").Id("cause").Dot("ID")
+			caused.Id("envelope").Dot("CorrelationID").Op("=").Id("cause").Dot("CorrelationID")
+			caused.If(jen.Id("envelope").Dot("CorrelationID").Op("==").Lit("")).Block(
+				jen.Id("envelope").Dot("CorrelationID").Op("=").Id("cause").Dot("ID"),
+			)
+		})
+		group.If(jen.List(jen.Id("source"), jen.Id("ok")).Op(":=").Id("ctx").Dot("Value").Call(jen.Id(eg.globalName("envelopeSourceKey")).Values()).Op(".").Parens(jen.String()), jen.Id("ok")).Block(
+			jen.Id("envelope").Dot("Source").Op("=").Id("source"),
+		)
+		group.If(jen.List(jen.Id("correlationID"), jen.Id("ok")).Op(":=").Id("ctx").Dot("Value").Call(jen.Id(eg.globalName("envelopeCorrelationKey")).Values()).Op(".").Parens(jen.String()), jen.Id("ok")).Block(
+			jen.Id("envelope").Dot("CorrelationID").Op("=").Id("correlationID"),
+		)
+		group.Return().Id("envelope")
+	}).Line()
+
+	if !eg.WithContext {
+		return code
+	}
+
+	code = code.Type().Id(eg.globalName("envelopeKey")).Struct().Line()
+	code = code.Type().Id(eg.globalName("envelopeSourceKey")).Struct().Line()
+	code = code.Type().Id(eg.globalName("envelopeCorrelationKey")).Struct().Line()
+
+	code = code.Func().Id(eg.globalName("ContextWithEnvelope")).Params(jen.Id("ctx").Qual("context", "Context"), jen.Id("envelope").Id(eg.globalName("Envelope"))).Qual("context", "Context").Block(
+		jen.Return().Qual("context", "WithValue").Call(jen.Id("ctx"), jen.Id(eg.globalName("envelopeKey")).Values(), jen.Id("envelope")),
+	).Line()
+
+	code = code.Func().Id(eg.globalName("EnvelopeFromContext")).Params(jen.Id("ctx").Qual("context", "Context")).Params(jen.Id(eg.globalName("Envelope")), jen.Bool()).Block(
+		jen.List(jen.Id("envelope"), jen.Id("ok")).Op(":=").Id("ctx").Dot("Value").Call(jen.Id(eg.globalName("envelopeKey")).Values()).Op(".").Parens(jen.Id(eg.globalName("Envelope"))),
+		jen.Return(jen.Id("envelope"), jen.Id("ok")),
+	).Line()
+
+	code = code.Func().Id(eg.globalName("ContextWithSource")).Params(jen.Id("ctx").Qual("context", "Context"), jen.Id("source").String()).Qual("context", "Context").Block(
+		jen.Return().Qual("context", "WithValue").Call(jen.Id("ctx"), jen.Id(eg.globalName("envelopeSourceKey")).Values(), jen.Id("source")),
+	).Line()
+
+	code = code.Func().Id(eg.globalName("ContextWithCorrelationID")).Params(jen.Id("ctx").Qual("context", "Context"), jen.Id("correlationID").String()).Qual("context", "Context").Block(
+		jen.Return().Qual("context", "WithValue").Call(jen.Id("ctx"), jen.Id(eg.globalName("envelopeCorrelationKey")).Values(), jen.Id("correlationID")),
+	).Line()
+	return code
+}
+
+// generateEnvelopeEmit generates Emit that wraps payload to the new envelope and emitEnvelope that dispatches already
+// wrapped events (used by the universal source to keep original metadata).
+func (eg EventGenerator) generateEnvelopeEmit(info *Struct, eventName, impl string) jen.Code {
+	code := jen.Func().Params(jen.Id("ev").Op("*").Id(impl)).Id(eg.emitFunc()).ParamsFunc(func(params *jen.Group) {
+		if eg.WithContext {
+			params.Id("ctx").Qual("context", "Context")
+		}
+		if !info.Empty() {
+			params.Id("payload").Add(info.Qual())
+		}
+	}).Add(eg.emitResult(info)).BlockFunc(func(group *jen.Group) {
+		if info.Empty() {
+			group.Id("payload").Op(":=").Add(info.Qual()).Values()
+		}
+		emit := jen.Id("ev").Dot("emitEnvelope").CallFunc(func(call *jen.Group) {
+			if eg.WithContext {
+				call.Id("ctx")
+				call.Id(eg.globalName("newEnvelope")).Call(jen.Id("ctx"), jen.Lit(eventName), jen.Id("payload"))
+			} else {
+				call.Id(eg.globalName("newEnvelope")).Call(jen.Lit(eventName), jen.Id("payload"))
+			}
+			call.Id("payload")
+		})
+		if eg.emitsError(info) {
+			group.Return(emit)
+		} else {
+			group.Add(emit)
+		}
+	}).Line()
+
+	code = code.Func().Params(jen.Id("ev").Op("*").Id(impl)).Id("emitEnvelope").ParamsFunc(func(params *jen.Group) {
+		if eg.WithContext {
+			params.Id("ctx").Qual("context", "Context")
+		}
+		params.Id("envelope").Id(eg.globalName("Envelope"))
+		params.Id("payload").Add(info.Qual())
+	}).Add(eg.emitResult(info)).BlockFunc(func(group *jen.Group) {
+		if eg.WithScopes {
+			group.If(jen.Id("ev").Dot("parent").Op("!=").Nil()).Add(eg.returnEmit(info, jen.Id("ev").Dot("parent").Dot("emitEnvelope").CallFunc(func(call *jen.Group) {
+				if eg.WithContext {
+					call.Id("ctx")
+				}
+				call.Id("envelope")
+				call.Id("payload")
+			})))
+		}
+		eg.validate(group, info, eventName)
+		if eg.WithContext {
+			group.Id("ctx").Op("=").Id(eg.globalName("ContextWithEnvelope")).Call(jen.Id("ctx"), jen.Id("envelope"))
+		}
+		eg.dispatch(group, eventName, jen.Id("envelope"))
+		if eg.envelopeSinks() {
+			group.Id("ev").Dot("lock").Dot("RLock").Call()
+			group.For(jen.List(jen.Id("_"), jen.Id("sink")).Op(":=").Range().Id("ev").Dot("sinks")).Block(
+				jen.Id("sink").Call(jen.Id("envelope")),
+			)
+			group.Id("ev").Dot("lock").Dot("RUnlock").Call()
+		}
+		if eg.emitsError(info) {
+			group.Return().Nil()
+		}
+	}).Line()
+	return code
+}
+
+// envelopeSinks is true if sinks should be stored separately from handlers: without context there is no other way to
+// pass the envelope created by emitter (and its ID) to the sink.
+func (eg EventGenerator) envelopeSinks() bool {
+	return eg.WithEnvelope && !eg.WithContext
+}
+
+// generateEnvelopeSinks generates subscription of sinks that receive the same envelope as mirror.
+func (eg EventGenerator) generateEnvelopeSinks(impl string) jen.Code {
+	code := jen.Func().Params(jen.Id("ev").Op("*").Id(impl)).Id("sink").Params(jen.Id("sink").Func().Params(jen.Id("envelope").Id(eg.globalName("Envelope")))).Params(jen.Id("unsubscribe").Func().Params()).BlockFunc(func(group *jen.Group) {
+		if eg.WithScopes {
+			group.If(jen.Id("ev").Dot("parent").Op("!=").Nil()).Block(
+				jen.Id("unsubscribe").Op("=").Id("ev").Dot("parent").Dot("sink").Call(jen.Id("sink")),
+				jen.Id("ev").Dot("lock").Dot("Lock").Call(),
+				jen.Id("ev").Dot("detach").Op("=").Append(jen.Id("ev").Dot("detach"), jen.Id("unsubscribe")),
+				jen.Id("ev").Dot("lock").Dot("Unlock").Call(),
+				jen.Return(jen.Id("unsubscribe")),
+			)
+		}
+		group.Id("ev").Dot("lock").Dot("Lock").Call()
+		group.Id("ev").Dot("lastSinkID").Op("++")
+		group.Id("id").Op(":=").Id("ev").Dot("lastSinkID")
+		group.Id("ev").Dot("sinks").Op("=").Append(jen.Id("ev").Dot("sinks"), jen.Id("sink"))
+		group.Id("ev").Dot("sinkIDs").Op("=").Append(jen.Id("ev").Dot("sinkIDs"), jen.Id("id"))
+		group.Id("ev").Dot("lock").Dot("Unlock").Call()
+		group.Return().Func().Params().Block(
+			jen.Id("ev").Dot("unsink").Call(jen.Id("id")),
+		)
+	}).Line()
+	code = code.Func().Params(jen.Id("ev").Op("*").Id(impl)).Id("unsink").Params(jen.Id("id").Uint64()).BlockFunc(func(group *jen.Group) {
+		group.Id("ev").Dot("lock").Dot("Lock").Call()
+		group.Defer().Id("ev").Dot("lock").Dot("Unlock").Call()
+		group.For(jen.List(jen.Id("i"), jen.Id("sinkID")).Op(":=").Range().Id("ev").Dot("sinkIDs")).BlockFunc(func(iter *jen.Group) {
+			iter.If(jen.Id("sinkID").Op("!=").Id("id")).Block(jen.Continue())
+			iter.Id("ev").Dot("sinks").Op("=").Append(jen.Id("ev").Dot("sinks").Index(jen.Empty(), jen.Id("i")), jen.Id("ev").Dot("sinks").Index(jen.Id("i").Op("+").Lit(1), jen.Empty()).Op("..."))
+			iter.Id("ev").Dot("sinkIDs").Op("=").Append(jen.Id("ev").Dot("sinkIDs").Index(jen.Empty(), jen.Id("i")), jen.Id("ev").Dot("sinkIDs").Index(jen.Id("i").Op("+").Lit(1), jen.Empty()).Op("..."))
+			iter.Return()
+		})
+	}).Line()
+	return code
+}
+
+// sinkEnvelope generates sink invocation inside subscriber (context mode only, see envelopeSinks). The envelope is taken
+// from the context prepared by emitter.
+func (eg EventGenerator) sinkEnvelope(eventName string) jen.Code {
+	return jen.List(jen.Id("envelope"), jen.Id("ok")).Op(":=").Id(eg.globalName("EnvelopeFromContext")).Call(jen.Id("ctx")).Line().
+		If(jen.Op("!").Id("ok").Op("||").Id("envelope").Dot("Event").Op("!=").Lit(eventName)).Block(
+		jen.Id("envelope").Op("=").Id(eg.globalName("newEnvelope")).Call(jen.Id("ctx"), jen.Lit(eventName), jen.Id("payload")),
+	).Line().
+		Id("sink").Call(jen.Id("ctx"), jen.Id("envelope"))
+}
+
+// generateEnvelopeSource generates universal emitter for already wrapped events (ex: received from external system).
+// Payload could be a value or a reference to the event type.
+func (eg EventGenerator) generateEnvelopeSource(eventBus string, events []string, types []*Struct) jen.Code {
+	emit := func(eventName string, payload jen.Code) jen.Code {
+		return jen.Id("ev").Dot(eventName).Dot("emitEnvelope").CallFunc(func(call *jen.Group) {
+			if eg.WithContext {
+				call.Id("ctx")
+			}
+			call.Id("envelope")
+			call.Add(payload)
+		})
+	}
+	return jen.Func().Params(jen.Id("ev").Op("*").Id(eventBus)).Id(eg.emitFunc() + "Envelope").ParamsFunc(func(params *jen.Group) {
+		if eg.WithContext {
+			params.Id("ctx").Qual("context", "Context")
+		}
+		params.Id("envelope").Id(eg.globalName("Envelope"))
+	}).BlockFunc(func(group *jen.Group) {
+		group.Add(eg.eventSwitch(jen.Id("envelope").Dot("Event"), events, func(i int, evGroup *jen.Group) {
+			eventType := types[i]
+			name := events[i]
+			cast := evGroup.If(jen.List(jen.Id("obj"), jen.Id("ok")).Op(":=").Id("envelope").Dot("Payload").Op(".").Parens(eventType.Qual()), jen.Id("ok")).Block(
+				emit(name, jen.Id("obj")),
+			).Else().If(jen.List(jen.Id("obj"), jen.Id("ok")).Op(":=").Id("envelope").Dot("Payload").Op(".").Parens(jen.Op("*").Add(eventType.Qual())), jen.Id("ok")).Block(
+				jen.Id("envelope").Dot("Payload").Op("=").Op("*").Id("obj"),
+				emit(name, jen.Op("*").Id("obj")),
+			)
+			if eventType.Empty() {
+				cast.Else().If(jen.Id("envelope").Dot("Payload").Op("==").Nil()).Block(
+					jen.Id("envelope").Dot("Payload").Op("=").Add(eventType.Qual()).Values(),
+					emit(name, jen.Add(eventType.Qual()).Values()),
+				)
+			}
+		}))
+	}).Line()
+}
@@ -0,0 +1,188 @@
+package structview
+
+import (
+	"errors"
+	"github.com/reddec/godetector"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"log"
+)
+
+type interfaceEvent struct {
+	Name    string
+	Payload *Struct
+}
+
+func (eg EventGenerator) isEventsInterface(name string) bool {
+	for _, iface := range eg.Interfaces {
+		if iface == name {
+			return true
+		}
+	}
+	return false
+}
+
+// interfaceEvents converts each method of the interface to the event: method name is an event name and the single
+// argument (optionally after context.Context) is a payload. Pointer argument means reference event. Result of the
+// method (optionally with error) makes request/reply event.
+func (eg EventGenerator) interfaceEvents(dir string, file *ast.File, iface *ast.InterfaceType) []interfaceEvent {
+	var ans []interfaceEvent
+	for _, method := range iface.Methods.List {
+		fn, ok := method.Type.(*ast.FuncType)
+		if !ok || len(method.Names) == 0 {
+			continue
+		}
+		var params []ast.Expr
+		for _, param := range fn.Params.List {
+			count := len(param.Names)
+			if count == 0 {
+				count = 1
+			}
+			for i := 0; i < count; i++ {
+				params = append(params, param.Type)
+			}
+		}
+		if len(params) > 0 && isContext(params[0]) {
+			params = params[1:]
+		}
+		for _, name := range method.Names {
+			if len(params) != 1 {
+				log.Println("method", name.Name, "should have exactly one payload argument - skipped")
+				continue
+			}
+			info, err := eg.resolvePayload(dir, file, params[0])
+			if err != nil {
+				log.Println("method", name.Name, ":", err)
+				continue
+			}
+			if result := replyResult(fn); result != nil {
+				info.Reply, err = eg.resolveReply(dir, file, result)
+				if err != nil {
+					log.Println("method", name.Name, ":", err)
+					continue
+				}
+			}
+			ans = append(ans, interfaceEvent{
+				Name:    name.Name,
+				Payload: info,
+			})
+		}
+	}
+	return ans
+}
+
+func (eg EventGenerator) resolvePayload(dir string, file *ast.File, expr ast.Expr) (*Struct, error) {
+	switch v := expr.(type) {
+	case *ast.StarExpr:
+		info, err := eg.resolvePayload(dir, file, v.X)
+		if err != nil {
+			return nil, err
+		}
+		ref := info.AsRef()
+		return &ref, nil
+	case *ast.Ident:
+		if types.Universe.Lookup(v.Name) != nil {
+			return nil, errors.New("payload should be a named type, not " + v.Name)
+		}
+		importPath, err := FindPackage(dir)
+		if err != nil {
+			return nil, err
+		}
+		return eg.loadPayload(dir, importPath, v.Name)
+	case *ast.SelectorExpr:
+		alias, ok := v.X.(*ast.Ident)
+		if !ok {
+			break
+		}
+		imp, err := godetector.ResolveImport(alias.Name, file, dir)
+		if err != nil {
+			return nil, err
+		}
+		return eg.loadPayload(imp.Location, imp.Path, v.Sel.Name)
+	}
+	return nil, errors.New("unsupported payload type")
+}
+
+// replyResult finds reply type of the method: single result or result and error.
+func replyResult(fn *ast.FuncType) ast.Expr {
+	var results []ast.Expr
+	if fn.Results != nil {
+		for _, field := range fn.Results.List {
+			count := len(field.Names)
+			if count == 0 {
+				count = 1
+			}
+			for i := 0; i < count; i++ {
+				results = append(results, field.Type)
+			}
+		}
+	}
+	if len(results) == 2 {
+		if ident, ok := results[1].(*ast.Ident); ok && ident.Name == "error" {
+			results = results[:1]
+		}
+	}
+	if len(results) != 1 {
+		return nil
+	}
+	return results[0]
+}
+
+func (eg EventGenerator) resolveReply(dir string, file *ast.File, expr ast.Expr) (*Struct, error) {
+	target := expr
+	if star, ok := target.(*ast.StarExpr); ok {
+		target = star.X
+	}
+	if ident, ok := target.(*ast.Ident); ok && types.Universe.Lookup(ident.Name) != nil {
+		return &Struct{Struct: ident.Name, Ref: target != expr}, nil
+	}
+	return eg.resolvePayload(dir, file, expr)
+}
+
+// loadPayload finds named type in the package directory. Non-struct types have empty definition.
+func (eg EventGenerator) loadPayload(dir, importPath, name string) (*Struct, error) {
+	fs := token.NewFileSet()
+	p, err := parser.ParseDir(fs, dir, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	for _, def := range p {
+		for _, file := range def.Files {
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					ts, ok := spec.(*ast.TypeSpec)
+					if !ok || ts.Name.Name != name {
+						continue
+					}
+					definition, _ := ts.Type.(*ast.StructType)
+					info := &Struct{
+						Struct:     name,
+						Dir:        dir,
+						Definition: definition,
+						ImportPath: importPath,
+					}
+					if eg.WithValidation {
+						info.Validator = findValidators(p)[name]
+					}
+					return info, nil
+				}
+			}
+		}
+	}
+	return nil, errors.New("type " + name + " not found")
+}
+
+func isContext(expr ast.Expr) bool {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Context" {
+		return false
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	return ok && pkg.Name == "context"
+}
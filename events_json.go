@@ -0,0 +1,54 @@
+package structview
+
+import (
+	"github.com/dave/jennifer/jen"
+)
+
+// generateJSONCodec generates JSON based emitter and marshaller for the event bus. Decoding is based on universal
+// payload fabric (Payload) and universal emitter (Emit).
+func (eg EventGenerator) generateJSONCodec(eventBus string, events []string, types []*Struct) jen.Code {
+	code := jen.Func().Params(jen.Id("ev").Op("*").Id(eventBus)).Id(eg.emitFunc() + "JSON").ParamsFunc(func(params *jen.Group) {
+		if eg.WithContext {
+			params.Id("ctx").Qual("context", "Context")
+		}
+		params.Id("eventName").String()
+		params.Id("data").Index().Byte()
+	}).Error().BlockFunc(func(group *jen.Group) {
+		group.Id("payload").Op(":=").Id("ev").Dot("Payload").Call(jen.Id("eventName"))
+		group.If(jen.Id("payload").Op("==").Nil()).Block(
+			jen.Return().Qual("fmt", "Errorf").Call(jen.Lit("%w %q"), jen.Id(eg.errorName("ErrUnknownEvent")), jen.Id("eventName")),
+		)
+		group.If(jen.Len(jen.Id("data")).Op("!=").Lit(0)).Block(
+			jen.If(jen.Err().Op(":=").Qual("encoding/json", "Unmarshal").Call(jen.Id("data"), jen.Id("payload")), jen.Err().Op("!=").Nil()).Block(
+				jen.Return().Qual("fmt", "Errorf").Call(jen.Lit("decode payload of event %s: %w"), jen.Id("eventName"), jen.Err()),
+			),
+		)
+		group.Return().Id("ev").Dot(eg.tryEmitFunc()).CallFunc(func(call *jen.Group) {
+			if eg.WithContext {
+				call.Id("ctx")
+			}
+			call.Id("eventName")
+			call.Id("payload")
+		})
+	}).Line()
+
+	code = code.Func().Params(jen.Id("ev").Op("*").Id(eventBus)).Id("MarshalEvent").Params(jen.Id("eventName").String(), jen.Id("payload").Interface()).Params(jen.Index().Byte(), jen.Error()).BlockFunc(func(group *jen.Group) {
+		group.Add(eg.eventSwitch(jen.Id("eventName"), events, func(i int, evGroup *jen.Group) {
+			eventType := types[i]
+			evGroup.Switch(jen.Id("payload").Op(".").Parens(jen.Type())).BlockFunc(func(sw *jen.Group) {
+				sw.CaseFunc(func(cases *jen.Group) {
+					cases.Add(eventType.Qual())
+					cases.Op("*").Add(eventType.Qual())
+					if eventType.Empty() {
+						cases.Nil()
+					}
+				}).Block(
+					jen.Return().Qual("encoding/json", "Marshal").Call(jen.Id("payload")),
+				)
+			})
+			evGroup.Return(jen.Nil(), jen.Qual("fmt", "Errorf").Call(jen.Lit("%w %T for event %s"), jen.Id(eg.errorName("ErrPayloadType")), jen.Id("payload"), jen.Id("eventName")))
+		}))
+		group.Return(jen.Nil(), jen.Qual("fmt", "Errorf").Call(jen.Lit("%w %q"), jen.Id(eg.errorName("ErrUnknownEvent")), jen.Id("eventName")))
+	}).Line()
+	return code
+}
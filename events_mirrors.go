@@ -0,0 +1,64 @@
+package structview
+
+import (
+	"github.com/dave/jennifer/jen"
+)
+
+// multiMirror checks that mirrors could be attached and detached at runtime
+func (eg EventGenerator) multiMirror() bool {
+	return eg.WithMirror && eg.WithMirrors && eg.WithBus
+}
+
+// dispatchMirrors generates invocation of all attached mirrors. Without mirrors it costs one atomic load.
+func (eg EventGenerator) dispatchMirrors(mirrorArgs ...jen.Code) jen.Code {
+	return jen.If(jen.List(jen.Id("mirrors"), jen.Id("_")).Op(":=").Id("ev").Dot("mirrors").Dot("Load").Call().Op(".").Parens(jen.Index().Add(eg.mirrorFunc())), jen.Len(jen.Id("mirrors")).Op(">").Lit(0)).Block(
+		jen.For(jen.List(jen.Id("_"), jen.Id("mirror")).Op(":=").Range().Id("mirrors")).Block(
+			jen.Id("mirror").Call(mirrorArgs...),
+		),
+	)
+}
+
+// busMirrorFields generates fields of event bus to keep attached mirrors
+func (eg EventGenerator) busMirrorFields(group *jen.Group) {
+	group.Id("mirrorLock").Qual("sync", "Mutex")
+	group.Id("lastMirrorID").Uint64()
+	group.Id("mirrorIDs").Index().Uint64()
+	group.Id("mirrorFuncs").Index().Add(eg.mirrorFunc())
+}
+
+// generateMirrors generates AddMirror and RemoveMirror for event bus. Each change publishes new copy of mirrors
+// to all events, so emit never locks for mirrors.
+func (eg EventGenerator) generateMirrors(eventBus string, events []string) jen.Code {
+	code := jen.Comment("AddMirror attaches mirror to all events and returns ID of mirror for RemoveMirror.").Line()
+	code = code.Func().Params(jen.Id("bus").Op("*").Id(eventBus)).Id("AddMirror").Params(jen.Id("mirror").Add(eg.mirrorFunc())).Uint64().Block(
+		jen.Id("bus").Dot("mirrorLock").Dot("Lock").Call(),
+		jen.Defer().Id("bus").Dot("mirrorLock").Dot("Unlock").Call(),
+		jen.Id("bus").Dot("lastMirrorID").Op("++"),
+		jen.Id("bus").Dot("mirrorIDs").Op("=").Append(jen.Id("bus").Dot("mirrorIDs"), jen.Id("bus").Dot("lastMirrorID")),
+		jen.Id("bus").Dot("mirrorFuncs").Op("=").Append(jen.Id("bus").Dot("mirrorFuncs"), jen.Id("mirror")),
+		jen.Id("bus").Dot("publishMirrors").Call(),
+		jen.Return().Id("bus").Dot("lastMirrorID"),
+	).Line()
+
+	code = code.Comment("RemoveMirror detaches mirror by ID. Unknown ID is ignored.").Line()
+	code = code.Func().Params(jen.Id("bus").Op("*").Id(eventBus)).Id("RemoveMirror").Params(jen.Id("id").Uint64()).Block(
+		jen.Id("bus").Dot("mirrorLock").Dot("Lock").Call(),
+		jen.Defer().Id("bus").Dot("mirrorLock").Dot("Unlock").Call(),
+		jen.For(jen.List(jen.Id("i"), jen.Id("mirrorID")).Op(":=").Range().Id("bus").Dot("mirrorIDs")).Block(
+			jen.If(jen.Id("mirrorID").Op("!=").Id("id")).Block(jen.Continue()),
+			jen.Id("bus").Dot("mirrorIDs").Op("=").Append(jen.Id("bus").Dot("mirrorIDs").Index(jen.Empty(), jen.Id("i")), jen.Id("bus").Dot("mirrorIDs").Index(jen.Id("i").Op("+").Lit(1), jen.Empty()).Op("...")),
+			jen.Id("bus").Dot("mirrorFuncs").Op("=").Append(jen.Id("bus").Dot("mirrorFuncs").Index(jen.Empty(), jen.Id("i")), jen.Id("bus").Dot("mirrorFuncs").Index(jen.Id("i").Op("+").Lit(1), jen.Empty()).Op("...")),
+			jen.Id("bus").Dot("publishMirrors").Call(),
+			jen.Return(),
+		),
+	).Line()
+
+	code = code.Func().Params(jen.Id("bus").Op("*").Id(eventBus)).Id("publishMirrors").Params().BlockFunc(func(group *jen.Group) {
+		group.Id("mirrors").Op(":=").Make(jen.Index().Add(eg.mirrorFunc()), jen.Len(jen.Id("bus").Dot("mirrorFuncs")))
+		group.Copy(jen.Id("mirrors"), jen.Id("bus").Dot("mirrorFuncs"))
+		for _, eventName := range events {
+			group.Id("bus").Dot(eventName).Dot("mirrors").Dot("Store").Call(jen.Id("mirrors"))
+		}
+	}).Line()
+	return code
+}
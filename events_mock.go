@@ -0,0 +1,74 @@
+package structview
+
+import (
+	"github.com/dave/jennifer/jen"
+)
+
+// emitterMethod generates signature of emitter method for the event
+func (eg EventGenerator) emitterMethod(statement *jen.Statement, eventType *Struct) *jen.Statement {
+	return statement.ParamsFunc(func(params *jen.Group) {
+		if eg.WithContext {
+			params.Id("ctx").Qual("context", "Context")
+		}
+		if !eventType.Empty() {
+			params.Id("payload").Add(eventType.Qual())
+		}
+	}).Add(eg.emitResult(eventType))
+}
+
+// generateEmitterInterface generates interface of emitter and mock implementation that records calls. Mock lets
+// test components that depends on emitter without event bus.
+func (eg EventGenerator) generateEmitterInterface(eventBus string, events []string, types []*Struct) jen.Code {
+	iface := eventBus + "Emitter"
+	mock := iface + "Mock"
+	emitter := "emitter" + eventBus
+
+	code := jen.Type().Id(iface).InterfaceFunc(func(group *jen.Group) {
+		for i, event := range events {
+			eg.emitterMethod(group.Id(event), types[i])
+		}
+	}).Line()
+
+	code = code.Var().Id("_").Id(iface).Op("=").Parens(jen.Op("*").Id(emitter)).Parens(jen.Nil()).Line()
+
+	code = code.Comment(mock + " records all calls. Methods with error returns Err.").Line()
+	code = code.Type().Id(mock).StructFunc(func(group *jen.Group) {
+		group.Id("Err").Error()
+		group.Id("lock").Qual("sync", "Mutex")
+		for i, event := range events {
+			group.Id("calls" + event).Index().Add(types[i].Qual())
+		}
+	}).Line()
+
+	code = code.Var().Id("_").Id(iface).Op("=").Parens(jen.Op("*").Id(mock)).Parens(jen.Nil()).Line()
+
+	for i, event := range events {
+		eventType := types[i]
+		code = eg.emitterMethod(code.Func().Params(jen.Id("mock").Op("*").Id(mock)).Id(event), eventType).BlockFunc(func(group *jen.Group) {
+			if eventType.Empty() {
+				group.Id("payload").Op(":=").Add(eventType.Qual()).Values()
+			}
+			group.Id("mock").Dot("lock").Dot("Lock").Call()
+			group.Defer().Id("mock").Dot("lock").Dot("Unlock").Call()
+			group.Id("mock").Dot("calls"+event).Op("=").Append(jen.Id("mock").Dot("calls"+event), jen.Id("payload"))
+			if eg.emitsError(eventType) {
+				group.Return().Id("mock").Dot("Err")
+			}
+		}).Line()
+
+		code = code.Func().Params(jen.Id("mock").Op("*").Id(mock)).Id(event+"Calls").Params().Index().Add(eventType.Qual()).Block(
+			jen.Id("mock").Dot("lock").Dot("Lock").Call(),
+			jen.Defer().Id("mock").Dot("lock").Dot("Unlock").Call(),
+			jen.Return().Append(jen.Index().Add(eventType.Qual()).Values(), jen.Id("mock").Dot("calls"+event).Op("...")),
+		).Line()
+	}
+
+	code = code.Func().Params(jen.Id("mock").Op("*").Id(mock)).Id("Reset").Params().BlockFunc(func(group *jen.Group) {
+		group.Id("mock").Dot("lock").Dot("Lock").Call()
+		for _, event := range events {
+			group.Id("mock").Dot("calls" + event).Op("=").Nil()
+		}
+		group.Id("mock").Dot("lock").Dot("Unlock").Call()
+	}).Line()
+	return code
+}
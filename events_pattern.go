@@ -0,0 +1,93 @@
+package structview
+
+import (
+	"github.com/dave/jennifer/jen"
+	"strings"
+	"unicode"
+)
+
+// eventTopic converts event name to the hierarchical topic: UserCreated -> user.created, HTTPRequest -> http.request.
+func eventTopic(eventName string) string {
+	var parts []string
+	var word []rune
+	runes := []rune(eventName)
+	flush := func() {
+		if len(word) > 0 {
+			parts = append(parts, strings.ToLower(string(word)))
+			word = nil
+		}
+	}
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '.' || r == '-':
+			flush()
+			continue
+		case unicode.IsUpper(r) && len(word) > 0:
+			prevUpper := unicode.IsUpper(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if !prevUpper || nextLower {
+				flush()
+			}
+		}
+		word = append(word, r)
+	}
+	flush()
+	return strings.Join(parts, ".")
+}
+
+// generatePatterns generates SubscribePattern for event bus and matchers. Pattern without dots is a glob (path.Match)
+// for event name, otherwise it's matched against hierarchical topic where * is exactly one segment and # is zero or
+// more segments. Matching done once during subscription.
+func (eg EventGenerator) generatePatterns(eventBus string, events []string, types []*Struct) jen.Code {
+	code := jen.Func().Params(jen.Id("bus").Op("*").Id(eventBus)).Id("SubscribePattern").Params(jen.Id("pattern").String(), jen.Id("sink").Add(eg.sinkFunc())).Params(jen.Id("unsubscribe").Func().Params()).BlockFunc(func(group *jen.Group) {
+		group.Var().Id("subscriptions").Index().Func().Params()
+		for i, eventName := range events {
+			// keep unsubscribe function of each matched event (handlers are always tracked with patterns)
+			subscribe := jen.Id("bus").Dot(eventName).Dot("subscribe").Call(eg.sinkHandler(eventName, types[i]))
+			if eg.envelopeSinks() {
+				subscribe = jen.Id("bus").Dot(eventName).Dot("sink").Call(jen.Id("sink"))
+			}
+			group.If(jen.Id(eg.globalName("matchEvent")).Call(jen.Id("pattern"), jen.Lit(eventName), jen.Lit(eventTopic(eventName)))).Block(
+				jen.Id("subscriptions").Op("=").Append(jen.Id("subscriptions"), subscribe),
+			)
+		}
+		group.Return().Func().Params().Block(
+			jen.For(jen.List(jen.Id("_"), jen.Id("unsubscribe")).Op(":=").Range().Id("subscriptions")).Block(
+				jen.Id("unsubscribe").Call(),
+			),
+		)
+	}).Line()
+
+	code = code.Func().Id(eg.globalName("matchEvent")).Params(jen.List(jen.Id("pattern"), jen.Id("eventName"), jen.Id("topic")).String()).Bool().Block(
+		jen.If(jen.Op("!").Qual("strings", "ContainsRune").Call(jen.Id("pattern"), jen.LitRune('.')).Op("&&").Id("pattern").Op("!=").Lit("#")).Block(
+			jen.List(jen.Id("ok"), jen.Id("_")).Op(":=").Qual("path", "Match").Call(jen.Id("pattern"), jen.Id("eventName")),
+			jen.Return().Id("ok"),
+		),
+		jen.Return().Id(eg.globalName("matchTopic")).Call(
+			jen.Qual("strings", "Split").Call(jen.Id("pattern"), jen.Lit(".")),
+			jen.Qual("strings", "Split").Call(jen.Id("topic"), jen.Lit(".")),
+		),
+	).Line()
+
+	next := jen.Id(eg.globalName("matchTopic")).Call(jen.Id("pattern").Index(jen.Lit(1), jen.Empty()), jen.Id("topic").Index(jen.Lit(1), jen.Empty()))
+	code = code.Func().Id(eg.globalName("matchTopic")).Params(jen.List(jen.Id("pattern"), jen.Id("topic")).Index().String()).Bool().Block(
+		jen.If(jen.Len(jen.Id("pattern")).Op("==").Lit(0)).Block(
+			jen.Return().Len(jen.Id("topic")).Op("==").Lit(0),
+		),
+		jen.Switch(jen.Id("pattern").Index(jen.Lit(0))).Block(
+			jen.Case(jen.Lit("#")).Block(
+				jen.For(jen.Id("i").Op(":=").Lit(0), jen.Id("i").Op("<=").Len(jen.Id("topic")), jen.Id("i").Op("++")).Block(
+					jen.If(jen.Id(eg.globalName("matchTopic")).Call(jen.Id("pattern").Index(jen.Lit(1), jen.Empty()), jen.Id("topic").Index(jen.Id("i"), jen.Empty()))).Block(
+						jen.Return().True(),
+					),
+				),
+				jen.Return().False(),
+			),
+			jen.Case(jen.Lit("*")).Block(
+				jen.Return().Len(jen.Id("topic")).Op(">").Lit(0).Op("&&").Add(next),
+			),
+		),
+		jen.Return().Len(jen.Id("topic")).Op(">").Lit(0).Op("&&").Id("pattern").Index(jen.Lit(0)).Op("==").Id("topic").Index(jen.Lit(0)).Op("&&").Add(next),
+	).Line()
+	return code
+}
@@ -0,0 +1,194 @@
+package structview
+
+import (
+	"github.com/dave/jennifer/jen"
+)
+
+// generateRate generates debounced, throttled and coalesced subscriptions. All of them built atop of Subscribe so
+// they don't affect mirror and sink. Delayed handlers are called from timer goroutine with context of the last event,
+// calls of the handler never overlap.
+func (eg EventGenerator) generateRate(info *Struct, impl string, handlerType jen.Code) jen.Code {
+	callHandler := func(ctx, payload jen.Code) jen.Code {
+		return jen.Id("handler").CallFunc(func(call *jen.Group) {
+			if eg.WithContext {
+				call.Add(ctx)
+			}
+			call.Add(payload)
+		})
+	}
+	// typed handler for Subscribe
+	subscriber := func(body func(group *jen.Group)) jen.Code {
+		return jen.Func().ParamsFunc(func(params *jen.Group) {
+			if eg.WithContext {
+				params.Id("ctx").Qual("context", "Context")
+			}
+			params.Id("payload").Add(info.Qual())
+		}).BlockFunc(body)
+	}
+	// subscribe and build stop function (handlers are always tracked with rate)
+	subscribe := func(group *jen.Group, handler jen.Code, stop ...jen.Code) {
+		group.Id("unsubscribe").Op(":=").Id("ev").Dot("subscribe").Call(handler)
+		group.Return().Func().Params().BlockFunc(func(fn *jen.Group) {
+			fn.Id("unsubscribe").Call()
+			fn.Id("lock").Dot("Lock").Call()
+			fn.Id("stopped").Op("=").True()
+			for _, st := range stop {
+				fn.Add(st)
+			}
+			fn.Id("lock").Dot("Unlock").Call()
+		})
+	}
+	stopTimer := jen.If(jen.Id("timer").Op("!=").Nil()).Block(jen.Id("timer").Dot("Stop").Call())
+	// state of the last event
+	lastVars := func(group *jen.Group) {
+		group.Id("last").Add(info.Qual())
+		if eg.WithContext {
+			group.Id("lastCtx").Qual("context", "Context")
+		}
+	}
+	saveLast := func(group *jen.Group) {
+		group.Id("last").Op("=").Id("payload")
+		if eg.WithContext {
+			group.Id("lastCtx").Op("=").Id("ctx")
+		}
+	}
+	loadLast := func(group *jen.Group) {
+		group.Id("payload").Op(":=").Id("last")
+		if eg.WithContext {
+			group.Id("ctx").Op(":=").Id("lastCtx")
+		}
+	}
+	// handler calls are serialized: immediate (emitter goroutine) and delayed (timer goroutine) calls never overlap
+	serialize := func(group *jen.Group) {
+		group.Id("calls").Dot("Lock").Call()
+		group.Defer().Id("calls").Dot("Unlock").Call()
+	}
+	params := func(params *jen.Group) {
+		params.Id("interval").Qual("time", "Duration")
+		params.Id("handler").Add(handlerType)
+	}
+
+	code := jen.Comment("SubscribeDebounced delivers the last event after interval of silence. Returned function stops delivery.").Line()
+	code = code.Func().Params(jen.Id("ev").Op("*").Id(impl)).Id("SubscribeDebounced").ParamsFunc(params).Params(jen.Id("stop").Func().Params()).BlockFunc(func(group *jen.Group) {
+		group.Var().DefsFunc(func(vars *jen.Group) {
+			vars.Id("lock").Qual("sync", "Mutex")
+			vars.Id("calls").Qual("sync", "Mutex")
+			vars.Id("timer").Op("*").Qual("time", "Timer")
+			vars.Id("stopped").Bool()
+			lastVars(vars)
+		})
+		subscribe(group, subscriber(func(handler *jen.Group) {
+			handler.Id("lock").Dot("Lock").Call()
+			handler.Defer().Id("lock").Dot("Unlock").Call()
+			handler.If(jen.Id("stopped")).Block(jen.Return())
+			saveLast(handler)
+			handler.Add(stopTimer)
+			handler.Id("timer").Op("=").Qual("time", "AfterFunc").Call(jen.Id("interval"), jen.Func().Params().BlockFunc(func(fire *jen.Group) {
+				fire.Id("lock").Dot("Lock").Call()
+				fire.If(jen.Id("stopped")).Block(
+					jen.Id("lock").Dot("Unlock").Call(),
+					jen.Return(),
+				)
+				loadLast(fire)
+				fire.Id("lock").Dot("Unlock").Call()
+				serialize(fire)
+				fire.Add(callHandler(jen.Id("ctx"), jen.Id("payload")))
+			}))
+		}), stopTimer)
+	}).Line()
+
+	code = code.Comment("SubscribeThrottled delivers at most one event per interval: the first immediately and the last one (if any) at the end of interval. Returned function stops delivery.").Line()
+	code = code.Func().Params(jen.Id("ev").Op("*").Id(impl)).Id("SubscribeThrottled").ParamsFunc(params).Params(jen.Id("stop").Func().Params()).BlockFunc(func(group *jen.Group) {
+		group.Var().DefsFunc(func(vars *jen.Group) {
+			vars.Id("lock").Qual("sync", "Mutex")
+			vars.Id("calls").Qual("sync", "Mutex")
+			vars.Id("timer").Op("*").Qual("time", "Timer")
+			vars.Id("stopped").Bool()
+			vars.Id("pending").Bool()
+			lastVars(vars)
+		})
+		group.Var().Id("flush").Func().Params()
+		group.Id("flush").Op("=").Func().Params().BlockFunc(func(fire *jen.Group) {
+			fire.Id("lock").Dot("Lock").Call()
+			fire.If(jen.Id("stopped").Op("||").Op("!").Id("pending")).Block(
+				jen.Id("timer").Op("=").Nil(),
+				jen.Id("lock").Dot("Unlock").Call(),
+				jen.Return(),
+			)
+			fire.Id("pending").Op("=").False()
+			loadLast(fire)
+			fire.Id("timer").Op("=").Qual("time", "AfterFunc").Call(jen.Id("interval"), jen.Id("flush"))
+			fire.Id("lock").Dot("Unlock").Call()
+			serialize(fire)
+			fire.Add(callHandler(jen.Id("ctx"), jen.Id("payload")))
+		})
+		subscribe(group, subscriber(func(handler *jen.Group) {
+			handler.Id("lock").Dot("Lock").Call()
+			handler.If(jen.Id("stopped")).Block(
+				jen.Id("lock").Dot("Unlock").Call(),
+				jen.Return(),
+			)
+			handler.If(jen.Id("timer").Op("!=").Nil()).BlockFunc(func(throttled *jen.Group) {
+				saveLast(throttled)
+				throttled.Id("pending").Op("=").True()
+				throttled.Id("lock").Dot("Unlock").Call()
+				throttled.Return()
+			})
+			handler.Id("timer").Op("=").Qual("time", "AfterFunc").Call(jen.Id("interval"), jen.Id("flush"))
+			handler.Id("lock").Dot("Unlock").Call()
+			serialize(handler)
+			handler.Add(callHandler(jen.Id("ctx"), jen.Id("payload")))
+		}), stopTimer)
+	}).Line()
+
+	code = code.Comment("SubscribeCoalesced collects events during interval and delivers only the last event for each key (in order of first appearance). Returned function stops delivery.").Line()
+	code = code.Func().Params(jen.Id("ev").Op("*").Id(impl)).Id("SubscribeCoalesced").ParamsFunc(func(group *jen.Group) {
+		group.Id("interval").Qual("time", "Duration")
+		group.Id("key").Func().Params(info.Qual()).String()
+		group.Id("handler").Add(handlerType)
+	}).Params(jen.Id("stop").Func().Params()).BlockFunc(func(group *jen.Group) {
+		group.Var().DefsFunc(func(vars *jen.Group) {
+			vars.Id("lock").Qual("sync", "Mutex")
+			vars.Id("calls").Qual("sync", "Mutex")
+			vars.Id("timer").Op("*").Qual("time", "Timer")
+			vars.Id("stopped").Bool()
+			vars.Id("keys").Index().String()
+			vars.Id("latest").Op("=").Make(jen.Map(jen.String()).Add(info.Qual()))
+			if eg.WithContext {
+				vars.Id("contexts").Op("=").Make(jen.Map(jen.String()).Qual("context", "Context"))
+			}
+		})
+		subscribe(group, subscriber(func(handler *jen.Group) {
+			handler.Id("k").Op(":=").Id("key").Call(jen.Id("payload"))
+			handler.Id("lock").Dot("Lock").Call()
+			handler.Defer().Id("lock").Dot("Unlock").Call()
+			handler.If(jen.Id("stopped")).Block(jen.Return())
+			handler.If(jen.List(jen.Id("_"), jen.Id("exists")).Op(":=").Id("latest").Index(jen.Id("k")), jen.Op("!").Id("exists")).Block(
+				jen.Id("keys").Op("=").Append(jen.Id("keys"), jen.Id("k")),
+			)
+			handler.Id("latest").Index(jen.Id("k")).Op("=").Id("payload")
+			if eg.WithContext {
+				handler.Id("contexts").Index(jen.Id("k")).Op("=").Id("ctx")
+			}
+			handler.If(jen.Id("timer").Op("!=").Nil()).Block(jen.Return())
+			handler.Id("timer").Op("=").Qual("time", "AfterFunc").Call(jen.Id("interval"), jen.Func().Params().BlockFunc(func(fire *jen.Group) {
+				fire.Id("lock").Dot("Lock").Call()
+				fire.Id("batchKeys").Op(",").Id("batch").Op(":=").List(jen.Id("keys"), jen.Id("latest"))
+				fire.Id("keys").Op(",").Id("latest").Op("=").List(jen.Nil(), jen.Make(jen.Map(jen.String()).Add(info.Qual())))
+				if eg.WithContext {
+					fire.Id("batchContexts").Op(":=").Id("contexts")
+					fire.Id("contexts").Op("=").Make(jen.Map(jen.String()).Qual("context", "Context"))
+				}
+				fire.Id("timer").Op("=").Nil()
+				fire.Id("done").Op(":=").Id("stopped")
+				fire.Id("lock").Dot("Unlock").Call()
+				fire.If(jen.Id("done")).Block(jen.Return())
+				serialize(fire)
+				fire.For(jen.List(jen.Id("_"), jen.Id("k")).Op(":=").Range().Id("batchKeys")).Block(
+					callHandler(jen.Id("batchContexts").Index(jen.Id("k")), jen.Id("batch").Index(jen.Id("k"))),
+				)
+			}))
+		}), stopTimer)
+	}).Line()
+	return code
+}
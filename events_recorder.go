@@ -0,0 +1,115 @@
+package structview
+
+import (
+	"github.com/dave/jennifer/jen"
+)
+
+// generateRecorder generates recorder that captures all events emitted in bus (mostly for tests) with helpers to
+// check recorded events and to replay them into another bus.
+func (eg EventGenerator) generateRecorder(eventBus string, events []string, types []*Struct) jen.Code {
+	recorder := eventBus + "Recorder"
+	record := eventBus + "Record"
+
+	code := jen.Type().Id(record).Struct(
+		jen.Id("Event").String(),
+		jen.Id("Payload").Interface(),
+	).Line()
+
+	code = code.Type().Id(recorder).Struct(
+		jen.Id("lock").Qual("sync", "RWMutex"),
+		jen.Id("records").Index().Id(record),
+	).Line()
+
+	code = code.Func().Params(jen.Id("bus").Op("*").Id(eventBus)).Id("Record").Params().Op("*").Id(recorder).BlockFunc(func(group *jen.Group) {
+		group.Id("rec").Op(":=").Op("&").Id(recorder).Values()
+		for i, eventName := range events {
+			group.Id("bus").Dot(eventName).Dot("Subscribe").Call(jen.Func().ParamsFunc(func(params *jen.Group) {
+				if eg.WithContext {
+					params.Id("_").Qual("context", "Context")
+				}
+				params.Id("payload").Add(types[i].Qual())
+			}).Block(
+				jen.Id("rec").Dot("add").Call(jen.Lit(eventName), jen.Id("payload")),
+			))
+		}
+		group.Return().Id("rec")
+	}).Line()
+
+	code = code.Func().Params(jen.Id("rec").Op("*").Id(recorder)).Id("add").Params(jen.Id("eventName").String(), jen.Id("payload").Interface()).Block(
+		jen.Id("rec").Dot("lock").Dot("Lock").Call(),
+		jen.Id("rec").Dot("records").Op("=").Append(jen.Id("rec").Dot("records"), jen.Id(record).Values(jen.Id("eventName"), jen.Id("payload"))),
+		jen.Id("rec").Dot("lock").Dot("Unlock").Call(),
+	).Line()
+
+	code = code.Func().Params(jen.Id("rec").Op("*").Id(recorder)).Id("Records").Params().Index().Id(record).Block(
+		jen.Id("rec").Dot("lock").Dot("RLock").Call(),
+		jen.Defer().Id("rec").Dot("lock").Dot("RUnlock").Call(),
+		jen.Id("cp").Op(":=").Make(jen.Index().Id(record), jen.Len(jen.Id("rec").Dot("records"))),
+		jen.Copy(jen.Id("cp"), jen.Id("rec").Dot("records")),
+		jen.Return().Id("cp"),
+	).Line()
+
+	code = code.Func().Params(jen.Id("rec").Op("*").Id(recorder)).Id("Count").Params(jen.Id("eventName").String()).Int().Block(
+		jen.Id("rec").Dot("lock").Dot("RLock").Call(),
+		jen.Defer().Id("rec").Dot("lock").Dot("RUnlock").Call(),
+		jen.Var().Id("count").Int(),
+		jen.For(jen.List(jen.Id("_"), jen.Id("record")).Op(":=").Range().Id("rec").Dot("records")).Block(
+			jen.If(jen.Id("record").Dot("Event").Op("==").Id("eventName")).Block(
+				jen.Id("count").Op("++"),
+			),
+		),
+		jen.Return().Id("count"),
+	).Line()
+
+	code = code.Func().Params(jen.Id("rec").Op("*").Id(recorder)).Id("Len").Params().Int().Block(
+		jen.Id("rec").Dot("lock").Dot("RLock").Call(),
+		jen.Defer().Id("rec").Dot("lock").Dot("RUnlock").Call(),
+		jen.Return().Len(jen.Id("rec").Dot("records")),
+	).Line()
+
+	code = code.Func().Params(jen.Id("rec").Op("*").Id(recorder)).Id("Reset").Params().Block(
+		jen.Id("rec").Dot("lock").Dot("Lock").Call(),
+		jen.Id("rec").Dot("records").Op("=").Nil(),
+		jen.Id("rec").Dot("lock").Dot("Unlock").Call(),
+	).Line()
+
+	for i, eventName := range events {
+		eventType := types[i]
+		code = code.Func().Params(jen.Id("rec").Op("*").Id(recorder)).Id("Last"+eventName).Params().Params(jen.Id("payload").Add(eventType.Qual()), jen.Id("ok").Bool()).Block(
+			jen.Id("rec").Dot("lock").Dot("RLock").Call(),
+			jen.Defer().Id("rec").Dot("lock").Dot("RUnlock").Call(),
+			jen.For(jen.Id("i").Op(":=").Len(jen.Id("rec").Dot("records")).Op("-").Lit(1), jen.Id("i").Op(">=").Lit(0), jen.Id("i").Op("--")).Block(
+				jen.If(jen.Id("rec").Dot("records").Index(jen.Id("i")).Dot("Event").Op("==").Lit(eventName)).Block(
+					jen.Return(jen.Id("rec").Dot("records").Index(jen.Id("i")).Dot("Payload").Op(".").Parens(eventType.Qual()), jen.True()),
+				),
+			),
+			jen.Return(),
+		).Line()
+	}
+
+	code = code.Func().Params(jen.Id("rec").Op("*").Id(recorder)).Id("Replay").ParamsFunc(func(params *jen.Group) {
+		if eg.WithContext {
+			params.Id("ctx").Qual("context", "Context")
+		}
+		params.Id("bus").Op("*").Id(eventBus)
+	}).BlockFunc(func(group *jen.Group) {
+		group.For(jen.List(jen.Id("_"), jen.Id("record")).Op(":=").Range().Id("rec").Dot("Records").Call()).BlockFunc(func(iter *jen.Group) {
+			iter.Switch(jen.Id("record").Dot("Event")).BlockFunc(func(sw *jen.Group) {
+				for i, eventName := range events {
+					eventType := types[i]
+					sw.Case(jen.Lit(eventName)).Block(
+						jen.Id("bus").Dot(eventName).Dot(eg.emitFunc()).CallFunc(func(call *jen.Group) {
+							if eg.WithContext {
+								call.Id("ctx")
+							}
+							if !eventType.Empty() {
+								call.Id("record").Dot("Payload").Op(".").Parens(eventType.Qual())
+							}
+						}),
+					)
+				}
+			})
+		})
+	}).Line()
+	return code
+}
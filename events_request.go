@@ -0,0 +1,169 @@
+package structview
+
+import (
+	"github.com/dave/jennifer/jen"
+	"go/types"
+	"strings"
+)
+
+// replyType parses reply option (reply=Quote or reply=*Quote) of event tag. Reply type should be declared in the same
+// package as payload or be a builtin type.
+func replyType(options []string, info *Struct) *Struct {
+	for _, option := range options {
+		if !strings.HasPrefix(option, "reply=") {
+			continue
+		}
+		name := strings.TrimSpace(option[len("reply="):])
+		ref := strings.HasPrefix(name, "*")
+		name = strings.TrimPrefix(name, "*")
+		reply := &Struct{
+			Struct:     name,
+			Dir:        info.Dir,
+			ImportPath: info.ImportPath,
+			Ref:        ref,
+		}
+		if types.Universe.Lookup(name) != nil {
+			reply.ImportPath = ""
+		}
+		return reply
+	}
+	return nil
+}
+
+func (eg EventGenerator) responderFunc(info *Struct) *jen.Statement {
+	return jen.Func().Params(jen.Qual("context", "Context"), info.Qual()).Params(info.Reply.Qual(), jen.Error())
+}
+
+// generateRequestErrors generates errors of request/reply events
+func (eg EventGenerator) generateRequestErrors() jen.Code {
+	return jen.Var().Defs(
+		jen.Id(eg.errorName("ErrNoResponder")).Op("=").Qual("errors", "New").Call(jen.Lit("no responder")),
+		jen.Id(eg.errorName("ErrResponderExists")).Op("=").Qual("errors", "New").Call(jen.Lit("responder already registered")),
+	).Line()
+}
+
+// generateRequest generates single responder registration and Request that waits for reply of the responder or
+// for the context.
+func (eg EventGenerator) generateRequest(info *Struct, eventName, impl string) jen.Code {
+	reply := info.Reply
+
+	code := jen.Func().Params(jen.Id("ev").Op("*").Id(impl)).Id("Respond").Params(jen.Id("responder").Add(eg.responderFunc(info))).Error().BlockFunc(func(group *jen.Group) {
+		if eg.WithScopes {
+			group.If(jen.Id("ev").Dot("parent").Op("!=").Nil()).Block(
+				jen.List(jen.Id("id"), jen.Err()).Op(":=").Id("ev").Dot("parent").Dot("respond").Call(jen.Id("responder")),
+				jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return().Err()),
+				jen.Id("parent").Op(":=").Id("ev").Dot("parent"),
+				jen.Id("ev").Dot("lock").Dot("Lock").Call(),
+				jen.Id("ev").Dot("responderID").Op("=").Id("id"),
+				jen.Id("ev").Dot("detach").Op("=").Append(jen.Id("ev").Dot("detach"), jen.Func().Params().Block(
+					jen.Id("parent").Dot("stopResponding").Call(jen.Id("id")),
+				)),
+				jen.Id("ev").Dot("lock").Dot("Unlock").Call(),
+				jen.Return().Nil(),
+			)
+			group.List(jen.Id("_"), jen.Err()).Op(":=").Id("ev").Dot("respond").Call(jen.Id("responder"))
+			group.Return().Err()
+			return
+		}
+		group.Id("ev").Dot("lock").Dot("Lock").Call()
+		group.Defer().Id("ev").Dot("lock").Dot("Unlock").Call()
+		group.If(jen.Id("ev").Dot("responder").Op("!=").Nil()).Block(
+			jen.Return().Qual("fmt", "Errorf").Call(jen.Lit("%w for event "+eventName), jen.Id(eg.errorName("ErrResponderExists"))),
+		)
+		group.Id("ev").Dot("responder").Op("=").Id("responder")
+		group.Return().Nil()
+	}).Line()
+
+	if eg.WithScopes {
+		// identify responder to let scope remove only own responder
+		code = code.Func().Params(jen.Id("ev").Op("*").Id(impl)).Id("respond").Params(jen.Id("responder").Add(eg.responderFunc(info))).Params(jen.Uint64(), jen.Error()).Block(
+			jen.Id("ev").Dot("lock").Dot("Lock").Call(),
+			jen.Defer().Id("ev").Dot("lock").Dot("Unlock").Call(),
+			jen.If(jen.Id("ev").Dot("responder").Op("!=").Nil()).Block(
+				jen.Return(jen.Lit(0), jen.Qual("fmt", "Errorf").Call(jen.Lit("%w for event "+eventName), jen.Id(eg.errorName("ErrResponderExists")))),
+			),
+			jen.Id("ev").Dot("lastID").Op("++"),
+			jen.Id("ev").Dot("responder").Op("=").Id("responder"),
+			jen.Id("ev").Dot("responderID").Op("=").Id("ev").Dot("lastID"),
+			jen.Return(jen.Id("ev").Dot("responderID"), jen.Nil()),
+		).Line()
+
+		code = code.Func().Params(jen.Id("ev").Op("*").Id(impl)).Id("stopResponding").Params(jen.Id("id").Uint64()).Block(
+			jen.Id("ev").Dot("lock").Dot("Lock").Call(),
+			jen.Defer().Id("ev").Dot("lock").Dot("Unlock").Call(),
+			jen.If(jen.Id("ev").Dot("responderID").Op("==").Id("id")).Block(
+				jen.Id("ev").Dot("responder").Op("=").Nil(),
+			),
+		).Line()
+	}
+
+	code = code.Func().Params(jen.Id("ev").Op("*").Id(impl)).Id("StopResponding").Params().BlockFunc(func(group *jen.Group) {
+		if eg.WithScopes {
+			// child removes only own responder (if it's still registered)
+			group.If(jen.Id("ev").Dot("parent").Op("!=").Nil()).Block(
+				jen.Id("ev").Dot("lock").Dot("Lock").Call(),
+				jen.Id("id").Op(":=").Id("ev").Dot("responderID"),
+				jen.Id("ev").Dot("responderID").Op("=").Lit(0),
+				jen.Id("ev").Dot("lock").Dot("Unlock").Call(),
+				jen.If(jen.Id("id").Op("!=").Lit(0)).Block(
+					jen.Id("ev").Dot("parent").Dot("stopResponding").Call(jen.Id("id")),
+				),
+				jen.Return(),
+			)
+		}
+		group.Id("ev").Dot("lock").Dot("Lock").Call()
+		group.Id("ev").Dot("responder").Op("=").Nil()
+		group.Id("ev").Dot("lock").Dot("Unlock").Call()
+	}).Line()
+
+	code = code.Func().Params(jen.Id("ev").Op("*").Id(impl)).Id("Request").ParamsFunc(func(params *jen.Group) {
+		params.Id("ctx").Qual("context", "Context")
+		if !info.Empty() {
+			params.Id("payload").Add(info.Qual())
+		}
+	}).Params(reply.Qual(), jen.Error()).BlockFunc(func(group *jen.Group) {
+		if eg.WithScopes {
+			group.If(jen.Id("ev").Dot("parent").Op("!=").Nil()).Block(
+				jen.Return().Id("ev").Dot("parent").Dot("Request").CallFunc(func(call *jen.Group) {
+					call.Id("ctx")
+					if !info.Empty() {
+						call.Id("payload")
+					}
+				}),
+			)
+		}
+		if info.Empty() {
+			group.Id("payload").Op(":=").Add(info.Qual()).Values()
+		}
+		group.Var().Id("reply").Add(reply.Qual())
+		if eg.emitsError(info) {
+			group.If(jen.Err().Op(":=").Id("payload").Dot("Validate").Call(), jen.Err().Op("!=").Nil()).Block(
+				jen.Return(jen.Id("reply"), jen.Qual("fmt", "Errorf").Call(jen.Lit("invalid payload of event "+eventName+": %w"), jen.Err())),
+			)
+		}
+		group.Id("ev").Dot("lock").Dot("RLock").Call()
+		group.Id("responder").Op(":=").Id("ev").Dot("responder")
+		group.Id("ev").Dot("lock").Dot("RUnlock").Call()
+		group.If(jen.Id("responder").Op("==").Nil()).Block(
+			jen.Return(jen.Id("reply"), jen.Qual("fmt", "Errorf").Call(jen.Lit("%w for event "+eventName), jen.Id(eg.errorName("ErrNoResponder")))),
+		)
+		group.Type().Id("result").Struct(
+			jen.Id("reply").Add(reply.Qual()),
+			jen.Id("err").Error(),
+		)
+		group.Id("done").Op(":=").Make(jen.Chan().Id("result"), jen.Lit(1))
+		group.Go().Func().Params().Block(
+			jen.List(jen.Id("reply"), jen.Err()).Op(":=").Id("responder").Call(jen.Id("ctx"), jen.Id("payload")),
+			jen.Id("done").Op("<-").Id("result").Values(jen.Id("reply"), jen.Err()),
+		).Call()
+		group.Select().Block(
+			jen.Case(jen.Op("<-").Id("ctx").Dot("Done").Call()).Block(
+				jen.Return(jen.Id("reply"), jen.Id("ctx").Dot("Err").Call()),
+			),
+			jen.Case(jen.Id("res").Op(":=").Op("<-").Id("done")).Block(
+				jen.Return(jen.Id("res").Dot("reply"), jen.Id("res").Dot("err")),
+			),
+		)
+	}).Line()
+	return code
+}
@@ -0,0 +1,84 @@
+package structview
+
+import (
+	"github.com/dave/jennifer/jen"
+)
+
+// generateStatsType generates type of event statistic
+func (eg EventGenerator) generateStatsType() jen.Code {
+	return jen.Type().Id(eg.globalName("EventStats")).Struct(
+		jen.Id("Subscribers").Int().Tag(map[string]string{"json": "subscribers"}),
+		jen.Id("Emitted").Uint64().Tag(map[string]string{"json": "emitted"}),
+		jen.Id("Panics").Uint64().Tag(map[string]string{"json": "panics"}),
+		jen.Id("Latency").Qual("time", "Duration").Tag(map[string]string{"json": "latency"}).Comment("cumulative latency of handlers"),
+		jen.Id("LastLatency").Qual("time", "Duration").Tag(map[string]string{"json": "last_latency"}).Comment("latency of the last invoked handler"),
+	).Line()
+}
+
+// generateStats generates Stats of event and invoke that measures handler and counts panics. Panic is propagated
+// after counting.
+func (eg EventGenerator) generateStats(info *Struct, impl string, handlerType jen.Code) jen.Code {
+	code := jen.Func().Params(jen.Id("ev").Op("*").Id(impl)).Id("Stats").Params().Id(eg.globalName("EventStats")).BlockFunc(func(group *jen.Group) {
+		if eg.WithScopes {
+			group.If(jen.Id("ev").Dot("parent").Op("!=").Nil()).Block(
+				jen.Return().Id("ev").Dot("parent").Dot("Stats").Call(),
+			)
+		}
+		group.Id("ev").Dot("statsLock").Dot("Lock").Call()
+		group.Id("stats").Op(":=").Id("ev").Dot("stats")
+		group.Id("ev").Dot("statsLock").Dot("Unlock").Call()
+		group.Id("ev").Dot("lock").Dot("RLock").Call()
+		group.Id("stats").Dot("Subscribers").Op("=").Len(jen.Id("ev").Dot("handlers"))
+		group.Id("ev").Dot("lock").Dot("RUnlock").Call()
+		group.Return().Id("stats")
+	}).Line()
+
+	code = code.Func().Params(jen.Id("ev").Op("*").Id(impl)).Id("invoke").ParamsFunc(func(params *jen.Group) {
+		if eg.WithContext {
+			params.Id("ctx").Qual("context", "Context")
+		}
+		params.Id("handler").Add(handlerType)
+		params.Id("payload").Add(info.Qual())
+	}).BlockFunc(func(group *jen.Group) {
+		group.Id("started").Op(":=").Qual("time", "Now").Call()
+		group.Defer().Func().Params().Block(
+			jen.Id("latency").Op(":=").Qual("time", "Since").Call(jen.Id("started")),
+			jen.Id("ev").Dot("statsLock").Dot("Lock").Call(),
+			jen.Id("ev").Dot("stats").Dot("Latency").Op("+=").Id("latency"),
+			jen.Id("ev").Dot("stats").Dot("LastLatency").Op("=").Id("latency"),
+			jen.If(jen.Id("r").Op(":=").Recover(), jen.Id("r").Op("!=").Nil()).Block(
+				jen.Id("ev").Dot("stats").Dot("Panics").Op("++"),
+				jen.Id("ev").Dot("statsLock").Dot("Unlock").Call(),
+				jen.Panic(jen.Id("r")),
+			),
+			jen.Id("ev").Dot("statsLock").Dot("Unlock").Call(),
+		).Call()
+		group.Id("handler").CallFunc(func(call *jen.Group) {
+			if eg.WithContext {
+				call.Id("ctx")
+			}
+			call.Id("payload")
+		})
+	}).Line()
+	return code
+}
+
+// generateBusStats generates statistic of all events in bus by event name and (optionally) expvar adapter
+func (eg EventGenerator) generateBusStats(eventBus string, events []string) jen.Code {
+	code := jen.Func().Params(jen.Id("bus").Op("*").Id(eventBus)).Id("Stats").Params().Map(jen.String()).Id(eg.globalName("EventStats")).Block(
+		jen.Return().Map(jen.String()).Id(eg.globalName("EventStats")).Values(jen.DictFunc(func(dict jen.Dict) {
+			for _, eventName := range events {
+				dict[jen.Lit(eventName)] = jen.Id("bus").Dot(eventName).Dot("Stats").Call()
+			}
+		})),
+	).Line()
+	if !eg.WithExpvar {
+		return code
+	}
+	code = code.Func().Params(jen.Id("bus").Op("*").Id(eventBus)).Id("PublishStats").Params(jen.Id("name").String()).Block(
+		jen.Qual("expvar", "Publish").Call(jen.Id("name"), jen.Qual("expvar", "Func").Call(jen.Func().Params().Interface().Block(
+			jen.Return().Id("bus").Dot("Stats").Call(),
+		))),
+	).Line()
+	return code
+}
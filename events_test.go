@@ -1,8 +1,13 @@
 package structview
 
 import (
+	"bytes"
 	"github.com/dave/jennifer/jen"
+	"io/ioutil"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -33,3 +38,291 @@ func TestEventGenerator_Generate(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+// payloads for generated events (package is compiled only in temporary module)
+const testPayloads = "testdata/events"
+
+// compileEvents generates events for test payloads to temporary module and checks that generated code compiles
+// (go vet). If runTests is true, tests of the payloads package are run against generated code.
+func compileEvents(t *testing.T, eg EventGenerator, runTests bool) {
+	dir, err := ioutil.TempDir("", "events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	err = ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/events\n\ngo 1.13\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := filepath.Glob(filepath.Join(testPayloads, "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") && !runTests {
+			continue
+		}
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, filepath.Base(file)), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	code, err := eg.Generate(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := jen.NewFilePathName("example.com/events", "events")
+	f.Add(code.Code)
+	if err := f.Save(filepath.Join(dir, "events.go")); err != nil {
+		t.Fatal(err)
+	}
+
+	args := []string{"vet", "."}
+	if runTests {
+		args = []string{"test", "-count=1", "."}
+	}
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		generated, _ := ioutil.ReadFile(filepath.Join(dir, "events.go"))
+		t.Fatalf("%v\n%s\n%s", err, out, generated)
+	}
+}
+
+func TestEventGenerator_GenerateChan(t *testing.T) {
+	eg := EventGenerator{
+		BusName:     "Events",
+		WithBus:     true,
+		WithContext: true,
+		WithChan:    true,
+	}
+	compileEvents(t, eg, false)
+}
+
+func TestEventGenerator_GenerateEnvelope(t *testing.T) {
+	eg := EventGenerator{
+		BusName:      "Events",
+		WithBus:      true,
+		WithMirror:   true,
+		WithSink:     true,
+		FromMirror:   true,
+		WithContext:  true,
+		WithEnvelope: true,
+	}
+	compileEvents(t, eg, false)
+}
+
+func TestEventGenerator_GenerateJSON(t *testing.T) {
+	eg := EventGenerator{
+		BusName:        "Events",
+		WithBus:        true,
+		FromMirror:     true,
+		FromIgnoreCase: true,
+		WithJSON:       true,
+	}
+	compileEvents(t, eg, false)
+}
+
+func TestEventGenerator_GenerateRecorder(t *testing.T) {
+	eg := EventGenerator{
+		BusName:      "Events",
+		WithBus:      true,
+		WithContext:  true,
+		WithRecorder: true,
+	}
+	compileEvents(t, eg, false)
+}
+
+func TestEventGenerator_GenerateScopes(t *testing.T) {
+	eg := EventGenerator{
+		BusName:     "Events",
+		WithBus:     true,
+		WithContext: true,
+		WithScopes:  true,
+	}
+	compileEvents(t, eg, false)
+}
+
+func TestEventGenerator_GenerateValidation(t *testing.T) {
+	eg := EventGenerator{
+		BusName:        "Events",
+		WithBus:        true,
+		WithContext:    true,
+		FromMirror:     true,
+		WithValidation: true,
+		Emitter:        "Emitter",
+	}
+	compileEvents(t, eg, false)
+}
+
+func TestEventGenerator_GenerateInterface(t *testing.T) {
+	eg := EventGenerator{
+		BusName:    "Bus",
+		WithBus:    true,
+		Private:    true,
+		Interfaces: []string{"testEvents"},
+	}
+	compileEvents(t, eg, false)
+}
+
+func TestEventGenerator_GeneratePatterns(t *testing.T) {
+	eg := EventGenerator{
+		BusName:      "Events",
+		WithBus:      true,
+		WithContext:  true,
+		WithScopes:   true,
+		WithPatterns: true,
+	}
+	compileEvents(t, eg, false)
+}
+
+func TestEventTopic(t *testing.T) {
+	cases := map[string]string{
+		"UserCreated":   "user.created",
+		"HTTPRequest":   "http.request",
+		"userID":        "user.id",
+		"order_placed":  "order.placed",
+		"Ping":          "ping",
+		"OrderV2Placed": "order.v2.placed",
+	}
+	for name, topic := range cases {
+		if got := eventTopic(name); got != topic {
+			t.Errorf("%s: expected %s, got %s", name, topic, got)
+		}
+	}
+}
+
+func TestEventGenerator_GenerateRequest(t *testing.T) {
+	eg := EventGenerator{
+		BusName:        "Events",
+		WithBus:        true,
+		WithScopes:     true,
+		WithValidation: true,
+	}
+	compileEvents(t, eg, false)
+}
+
+func TestEventGenerator_GenerateStats(t *testing.T) {
+	eg := EventGenerator{
+		BusName:     "Events",
+		WithBus:     true,
+		WithContext: true,
+		WithStats:   true,
+		WithExpvar:  true,
+	}
+	compileEvents(t, eg, false)
+}
+
+func TestEventGenerator_GenerateTracing(t *testing.T) {
+	eg := EventGenerator{
+		BusName:     "Events",
+		WithBus:     true,
+		WithContext: true,
+		WithStats:   true,
+		WithTracing: true,
+	}
+	compileEvents(t, eg, false)
+}
+
+func TestEventGenerator_GenerateStable(t *testing.T) {
+	eg := EventGenerator{
+		BusName:     "Events",
+		WithBus:     true,
+		WithMirror:  true,
+		WithSink:    true,
+		WithContext: true,
+		FromMirror:  true,
+		WithJSON:    true,
+		Emitter:     "Emitter",
+		Listener:    "SubscribeAll",
+		Hints: map[string]string{
+			"Alpha":   "event",
+			"Beta":    "event",
+			"Gamma":   "query",
+			"Delta":   "eventIDs",
+			"Epsilon": "event",
+		},
+	}
+	render := func() string {
+		code, err := eg.Generate(testPayloads)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f := jen.NewFile("xyz")
+		f.Add(code.Code)
+		var out bytes.Buffer
+		if err := f.Render(&out); err != nil {
+			t.Fatal(err)
+		}
+		return out.String()
+	}
+	expected := render()
+	for i := 0; i < 10; i++ {
+		if render() != expected {
+			t.Fatal("generated code is not stable")
+		}
+	}
+}
+
+func TestEventGenerator_GenerateEmitterInterface(t *testing.T) {
+	eg := EventGenerator{
+		BusName:              "Events",
+		WithBus:              true,
+		WithContext:          true,
+		WithValidation:       true,
+		Emitter:              "Emitter",
+		WithEmitterInterface: true,
+	}
+	compileEvents(t, eg, false)
+}
+
+func TestEventGenerator_GenerateRate(t *testing.T) {
+	eg := EventGenerator{
+		BusName:     "Events",
+		WithBus:     true,
+		WithContext: true,
+		WithScopes:  true,
+		WithRate:    true,
+	}
+	compileEvents(t, eg, false)
+}
+
+func TestEventGenerator_GenerateMirrors(t *testing.T) {
+	eg := EventGenerator{
+		BusName:     "Events",
+		WithBus:     true,
+		WithMirror:  true,
+		WithMirrors: true,
+		WithContext: true,
+	}
+	compileEvents(t, eg, false)
+}
+
+// TestEventGenerator_Behavior runs tests of testdata/events against generated code: chan, scopes, validation,
+// request/reply, mirrors, rate, tracing, patterns, envelope, JSON codec, stats and emitter mock.
+func TestEventGenerator_Behavior(t *testing.T) {
+	eg := EventGenerator{
+		BusName:              "Events",
+		WithBus:              true,
+		WithContext:          true,
+		WithChan:             true,
+		WithScopes:           true,
+		WithValidation:       true,
+		WithMirror:           true,
+		WithMirrors:          true,
+		WithRate:             true,
+		WithTracing:          true,
+		WithPatterns:         true,
+		WithEnvelope:         true,
+		WithJSON:             true,
+		FromMirror:           true,
+		WithStats:            true,
+		Emitter:              "Emitter",
+		WithEmitterInterface: true,
+	}
+	compileEvents(t, eg, true)
+}
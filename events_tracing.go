@@ -0,0 +1,143 @@
+package structview
+
+import (
+	"github.com/dave/jennifer/jen"
+)
+
+// tracing is possible only in context mode
+func (eg EventGenerator) tracing() bool {
+	return eg.WithTracing && eg.WithContext
+}
+
+func (eg EventGenerator) spanAttributes(eventName string, extra ...jen.Code) jen.Code {
+	return jen.Map(jen.String()).String().Values(jen.DictFunc(func(dict jen.Dict) {
+		dict[jen.Lit("event")] = jen.Lit(eventName)
+		if eg.WithEnvelope {
+			dict[jen.Lit("event.id")] = jen.Id("envelope").Dot("ID")
+		}
+		for i := 0; i+1 < len(extra); i += 2 {
+			dict[extra[i]] = extra[i+1]
+		}
+	}))
+}
+
+// generateTracer generates pluggable tracer interfaces (could be adapted to OpenTelemetry) and in-memory tracer
+// mostly for tests.
+func (eg EventGenerator) generateTracer() jen.Code {
+	code := jen.Comment(eg.globalName("Tracer") + " starts span for emitted event and for each handler. Returned context is passed to handlers.").Line()
+	code = code.Type().Id(eg.globalName("Tracer")).Interface(
+		jen.Id("Start").Params(jen.Id("ctx").Qual("context", "Context"), jen.Id("spanName").String(), jen.Id("attributes").Map(jen.String()).String()).Params(jen.Qual("context", "Context"), jen.Id(eg.globalName("Span"))),
+	).Line()
+	code = code.Type().Id(eg.globalName("Span")).Interface(
+		jen.Id("RecordError").Params(jen.Err().Error()),
+		jen.Id("End").Params(),
+	).Line()
+
+	code = code.Comment(eg.globalName("TraceSpan") + " is a span recorded by " + eg.globalName("MemoryTracer")).Line()
+	code = code.Type().Id(eg.globalName("TraceSpan")).Struct(
+		jen.Id("Name").String(),
+		jen.Id("Parent").String(),
+		jen.Id("Attributes").Map(jen.String()).String(),
+		jen.Id("Err").Error(),
+		jen.Id("Ended").Bool(),
+	).Line()
+
+	code = code.Comment(eg.globalName("MemoryTracer") + " keeps all spans in memory").Line()
+	code = code.Type().Id(eg.globalName("MemoryTracer")).Struct(
+		jen.Id("lock").Qual("sync", "Mutex"),
+		jen.Id("spans").Index().Op("*").Id(eg.globalName("TraceSpan")),
+	).Line()
+
+	code = code.Type().Id(eg.globalName("memorySpanKey")).Struct().Line()
+
+	code = code.Type().Id(eg.globalName("memorySpan")).Struct(
+		jen.Id("tracer").Op("*").Id(eg.globalName("MemoryTracer")),
+		jen.Id("span").Op("*").Id(eg.globalName("TraceSpan")),
+	).Line()
+
+	code = code.Func().Params(jen.Id("mt").Op("*").Id(eg.globalName("MemoryTracer"))).Id("Start").Params(jen.Id("ctx").Qual("context", "Context"), jen.Id("spanName").String(), jen.Id("attributes").Map(jen.String()).String()).Params(jen.Qual("context", "Context"), jen.Id(eg.globalName("Span"))).Block(
+		jen.Id("span").Op(":=").Op("&").Id(eg.globalName("TraceSpan")).Values(jen.Dict{
+			jen.Id("Name"):       jen.Id("spanName"),
+			jen.Id("Attributes"): jen.Id("attributes"),
+		}),
+		jen.If(jen.List(jen.Id("parent"), jen.Id("ok")).Op(":=").Id("ctx").Dot("Value").Call(jen.Id(eg.globalName("memorySpanKey")).Values()).Op(".").Parens(jen.Op("*").Id(eg.globalName("TraceSpan"))), jen.Id("ok")).Block(
+			jen.Id("span").Dot("Parent").Op("=").Id("parent").Dot("Name"),
+		),
+		jen.Id("mt").Dot("lock").Dot("Lock").Call(),
+		jen.Id("mt").Dot("spans").Op("=").Append(jen.Id("mt").Dot("spans"), jen.Id("span")),
+		jen.Id("mt").Dot("lock").Dot("Unlock").Call(),
+		jen.Return(jen.Qual("context", "WithValue").Call(jen.Id("ctx"), jen.Id(eg.globalName("memorySpanKey")).Values(), jen.Id("span")), jen.Op("&").Id(eg.globalName("memorySpan")).Values(jen.Id("mt"), jen.Id("span"))),
+	).Line()
+
+	code = code.Comment("Spans returns copy of all started spans in order of start").Line()
+	code = code.Func().Params(jen.Id("mt").Op("*").Id(eg.globalName("MemoryTracer"))).Id("Spans").Params().Index().Id(eg.globalName("TraceSpan")).Block(
+		jen.Id("mt").Dot("lock").Dot("Lock").Call(),
+		jen.Defer().Id("mt").Dot("lock").Dot("Unlock").Call(),
+		jen.Id("spans").Op(":=").Make(jen.Index().Id(eg.globalName("TraceSpan")), jen.Len(jen.Id("mt").Dot("spans"))),
+		jen.For(jen.List(jen.Id("i"), jen.Id("span")).Op(":=").Range().Id("mt").Dot("spans")).Block(
+			jen.Id("spans").Index(jen.Id("i")).Op("=").Op("*").Id("span"),
+		),
+		jen.Return().Id("spans"),
+	).Line()
+
+	code = code.Func().Params(jen.Id("mt").Op("*").Id(eg.globalName("MemoryTracer"))).Id("Reset").Params().Block(
+		jen.Id("mt").Dot("lock").Dot("Lock").Call(),
+		jen.Id("mt").Dot("spans").Op("=").Nil(),
+		jen.Id("mt").Dot("lock").Dot("Unlock").Call(),
+	).Line()
+
+	code = code.Func().Params(jen.Id("ms").Op("*").Id(eg.globalName("memorySpan"))).Id("RecordError").Params(jen.Err().Error()).Block(
+		jen.Id("ms").Dot("tracer").Dot("lock").Dot("Lock").Call(),
+		jen.Id("ms").Dot("span").Dot("Err").Op("=").Err(),
+		jen.Id("ms").Dot("tracer").Dot("lock").Dot("Unlock").Call(),
+	).Line()
+
+	code = code.Func().Params(jen.Id("ms").Op("*").Id(eg.globalName("memorySpan"))).Id("End").Params().Block(
+		jen.Id("ms").Dot("tracer").Dot("lock").Dot("Lock").Call(),
+		jen.Id("ms").Dot("span").Dot("Ended").Op("=").True(),
+		jen.Id("ms").Dot("tracer").Dot("lock").Dot("Unlock").Call(),
+	).Line()
+	return code
+}
+
+// generateTracing generates tracer setter and traced invocation of handler. Panic of handler recorded to the span
+// and propagated.
+func (eg EventGenerator) generateTracing(info *Struct, eventName, impl string, handlerType jen.Code) jen.Code {
+	code := jen.Func().Params(jen.Id("ev").Op("*").Id(impl)).Id("SetTracer").Params(jen.Id("tracer").Id(eg.globalName("Tracer"))).Block(
+		jen.Id("ev").Dot("lock").Dot("Lock").Call(),
+		jen.Id("ev").Dot("tracer").Op("=").Id("tracer"),
+		jen.Id("ev").Dot("lock").Dot("Unlock").Call(),
+	).Line()
+
+	code = code.Func().Params(jen.Id("ev").Op("*").Id(impl)).Id("traceHandler").ParamsFunc(func(params *jen.Group) {
+		params.Id("ctx").Qual("context", "Context")
+		params.Id("tracer").Id(eg.globalName("Tracer"))
+		params.Id("index").Int()
+		params.Id("handler").Add(handlerType)
+		params.Id("payload").Add(info.Qual())
+		if eg.WithEnvelope {
+			params.Id("envelope").Id(eg.globalName("Envelope"))
+		}
+	}).BlockFunc(func(group *jen.Group) {
+		group.List(jen.Id("ctx"), jen.Id("span")).Op(":=").Id("tracer").Dot("Start").Call(jen.Id("ctx"), jen.Lit("handle "+eventName), eg.spanAttributes(eventName, jen.Lit("handler"), jen.Qual("strconv", "Itoa").Call(jen.Id("index"))))
+		group.Defer().Func().Params().Block(
+			jen.If(jen.Id("r").Op(":=").Recover(), jen.Id("r").Op("!=").Nil()).Block(
+				jen.Id("span").Dot("RecordError").Call(jen.Qual("fmt", "Errorf").Call(jen.Lit("panic: %v"), jen.Id("r"))),
+				jen.Id("span").Dot("End").Call(),
+				jen.Panic(jen.Id("r")),
+			),
+			jen.Id("span").Dot("End").Call(),
+		).Call()
+		group.Add(eg.invokeHandler())
+	}).Line()
+	return code
+}
+
+func (eg EventGenerator) generateBusTracing(eventBus string, events []string) jen.Code {
+	return jen.Func().Params(jen.Id("bus").Op("*").Id(eventBus)).Id("SetTracer").Params(jen.Id("tracer").Id(eg.globalName("Tracer"))).Op("*").Id(eventBus).BlockFunc(func(group *jen.Group) {
+		for _, eventName := range events {
+			group.Id("bus").Dot(eventName).Dot("SetTracer").Call(jen.Id("tracer"))
+		}
+		group.Return().Id("bus")
+	}).Line()
+}
@@ -82,9 +82,15 @@ type Struct struct {
 	Definition *ast.StructType
 	ImportPath string
 	Ref        bool
+	Validator  bool    // has method Validate() error
+	Reply      *Struct // reply type for request/reply event
 }
 
 func (s Struct) Empty() bool {
+	if s.Definition == nil {
+		// non-struct types always have a value
+		return false
+	}
 	return s.Definition.Fields == nil || len(s.Definition.Fields.List) == 0
 }
 
@@ -94,6 +100,12 @@ func (s Struct) AsRef() Struct {
 	return cp
 }
 
+func (s Struct) AsValue() Struct {
+	cp := s
+	cp.Ref = false
+	return cp
+}
+
 func (s Struct) Qual() jen.Code {
 	var tp = jen.Empty()
 	if s.Ref {
@@ -0,0 +1,208 @@
+package eventlog
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+)
+
+type record struct {
+	Offset  uint64          `json:"offset"`
+	Event   string          `json:"event"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// storage of log (implemented by *os.File)
+type storage interface {
+	io.WriteCloser
+	io.Seeker
+	Truncate(size int64) error
+	Sync() error
+}
+
+// Durable append-only log of events. Each event stored as one JSON line with sequence number (offset),
+// event name and payload. Compatible with mirror and sink of generated event bus.
+type Log struct {
+	lock     sync.Mutex
+	filename string
+	file     storage
+	next     uint64
+	size     int64 // size of valid content
+}
+
+// Open or create events log. Incomplete tail (ex: after crash during write) is truncated. Corrupted records before
+// the tail are reported as error.
+func Open(filename string) (*Log, error) {
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	next, size, err := scan(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	if err := f.Truncate(size); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	if _, err := f.Seek(size, io.SeekStart); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return &Log{
+		filename: filename,
+		file:     f,
+		next:     next,
+		size:     size,
+	}, nil
+}
+
+// Append event to the log. Payload encoded to JSON. Returns offset of the event.
+func (l *Log) Append(eventName string, payload interface{}) (uint64, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return 0, err
+	}
+	return l.AppendJSON(eventName, data)
+}
+
+// AppendJSON appends already encoded event (ex: by generated MarshalEvent) to the log. Returns offset of the event.
+func (l *Log) AppendJSON(eventName string, data []byte) (uint64, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	line, err := json.Marshal(record{
+		Offset:  l.next,
+		Event:   eventName,
+		Payload: data,
+	})
+	if err != nil {
+		return 0, err
+	}
+	line = append(line, '\n')
+	if _, err := l.file.Write(line); err != nil {
+		// rollback partial write, otherwise the next record will be appended to the broken line
+		if rerr := l.rollback(); rerr != nil {
+			return 0, fmt.Errorf("%v (rollback failed: %v)", err, rerr)
+		}
+		return 0, err
+	}
+	l.size += int64(len(line))
+	offset := l.next
+	l.next++
+	return offset, nil
+}
+
+func (l *Log) rollback() error {
+	if err := l.file.Truncate(l.size); err != nil {
+		return err
+	}
+	_, err := l.file.Seek(l.size, io.SeekStart)
+	return err
+}
+
+// Feed event to the log. Signature is compatible with mirror and sink (without context) of generated event bus.
+// Errors are logged.
+func (l *Log) Feed(eventName string, payload interface{}) {
+	if _, err := l.Append(eventName, payload); err != nil {
+		log.Println("failed append event", eventName, "to log", l.filename, ":", err)
+	}
+}
+
+// FeedContext is same as Feed but compatible with sink in context mode.
+func (l *Log) FeedContext(_ context.Context, eventName string, payload interface{}) {
+	l.Feed(eventName, payload)
+}
+
+// Next offset that will be assigned to the new event.
+func (l *Log) Next() uint64 {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.next
+}
+
+// Sync flushes written events to the disk.
+func (l *Log) Sync() error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.file.Sync()
+}
+
+// Close log file.
+func (l *Log) Close() error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.file.Close()
+}
+
+// Replay events starting from offset (inclusive) through emitter. Emitter signature is compatible with generated
+// EmitJSON (without context). Replay stops on the first emitter error. Returns offset of the next not replayed event.
+// Only events written before the call are replayed, so it's safe to replay into the event bus that mirrors to the log.
+func (l *Log) Replay(offset uint64, emit func(eventName string, data []byte) error) (uint64, error) {
+	end := l.Next()
+	f, err := os.Open(l.filename)
+	if err != nil {
+		return offset, err
+	}
+	defer f.Close()
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// incomplete lines are not yet written events
+			return offset, nil
+		} else if err != nil {
+			return offset, err
+		}
+		var rec record
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return offset, err
+		}
+		if rec.Offset >= end {
+			return offset, nil
+		}
+		if rec.Offset < offset {
+			continue
+		}
+		if err := emit(rec.Event, rec.Payload); err != nil {
+			return rec.Offset, err
+		}
+		offset = rec.Offset + 1
+	}
+}
+
+// ReplayContext is same as Replay but compatible with generated EmitJSON in context mode.
+func (l *Log) ReplayContext(ctx context.Context, offset uint64, emit func(ctx context.Context, eventName string, data []byte) error) (uint64, error) {
+	return l.Replay(offset, func(eventName string, data []byte) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return emit(ctx, eventName, data)
+	})
+}
+
+// scan log and find next offset and size of valid content. Only the last line without new line is treated as torn
+// tail, broken complete lines are errors.
+func scan(f *os.File) (next uint64, size int64, err error) {
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			return next, size, nil
+		} else if err != nil {
+			return 0, 0, err
+		}
+		var rec record
+		if err := json.Unmarshal(bytes.TrimSpace(line), &rec); err != nil {
+			return 0, 0, fmt.Errorf("corrupted record at byte %d: %v", size, err)
+		}
+		next = rec.Offset + 1
+		size += int64(len(line))
+	}
+}
@@ -0,0 +1,208 @@
+package eventlog
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLog_Replay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "events.log")
+
+	events, err := Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	events.Feed("UserCreated", map[string]int{"ID": 1})
+	events.Feed("UserRemoved", map[string]int{"ID": 1})
+	events.Feed("UserCreated", map[string]int{"ID": 2})
+	if err := events.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// simulate crash during write
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(`{"offset":3,"event":"Use`)
+	_ = f.Close()
+
+	events, err = Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer events.Close()
+	if events.Next() != 3 {
+		t.Fatal("expected next offset 3, got", events.Next())
+	}
+	offset, err := events.Append("UserCreated", map[string]int{"ID": 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if offset != 3 {
+		t.Fatal("expected offset 3, got", offset)
+	}
+
+	var replayed []string
+	next, err := events.Replay(1, func(eventName string, data []byte) error {
+		replayed = append(replayed, eventName+" "+string(data))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != 4 {
+		t.Fatal("expected next offset 4, got", next)
+	}
+	expected := []string{`UserRemoved {"ID":1}`, `UserCreated {"ID":2}`, `UserCreated {"ID":3}`}
+	if len(replayed) != len(expected) {
+		t.Fatal(replayed)
+	}
+	for i := range expected {
+		if replayed[i] != expected[i] {
+			t.Error(replayed[i], "!=", expected[i])
+		}
+	}
+
+	failed := errors.New("failed")
+	next, err = events.Replay(0, func(eventName string, data []byte) error {
+		if eventName == "UserRemoved" {
+			return failed
+		}
+		return nil
+	})
+	if err != failed {
+		t.Fatal(err)
+	}
+	if next != 1 {
+		t.Fatal("expected stop at offset 1, got", next)
+	}
+}
+
+func TestLog_ReplayToMirror(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	events, err := Open(filepath.Join(dir, "events.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer events.Close()
+	events.Feed("UserCreated", 1)
+	events.Feed("UserCreated", 2)
+	events.Feed("UserCreated", 3)
+
+	// event bus with the log attached as mirror: every replayed event is appended again
+	var replayed int
+	next, err := events.Replay(0, func(eventName string, data []byte) error {
+		replayed++
+		if replayed > 10 {
+			t.Fatal("replay doesn't stop")
+		}
+		events.Feed(eventName, json.RawMessage(data))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed != 3 || next != 3 {
+		t.Errorf("expected 3 replayed events and next offset 3, got %d and %d", replayed, next)
+	}
+	if events.Next() != 6 {
+		t.Error("expected next offset 6, got", events.Next())
+	}
+}
+
+// shortWriter writes half of the data and fails once
+type shortWriter struct {
+	storage
+	failed bool
+}
+
+func (sw *shortWriter) Write(data []byte) (int, error) {
+	if sw.failed {
+		return sw.storage.Write(data)
+	}
+	sw.failed = true
+	n, _ := sw.storage.Write(data[:len(data)/2])
+	return n, errors.New("disk full")
+}
+
+func TestLog_Rollback(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "events.log")
+
+	events, err := Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	events.Feed("UserCreated", 1)
+	events.file = &shortWriter{storage: events.file}
+	if _, err := events.Append("UserCreated", 2); err == nil {
+		t.Fatal("expected write error")
+	}
+	events.Feed("UserCreated", 3)
+	if err := events.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	events, err = Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer events.Close()
+	var replayed []string
+	_, err = events.Replay(0, func(eventName string, data []byte) error {
+		replayed = append(replayed, string(data))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(replayed, ",") != "1,3" {
+		t.Error("unexpected events", replayed)
+	}
+}
+
+func TestOpen_Corrupted(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "events.log")
+
+	content := `{"offset":0,"event":"UserCreated","payload":1}` + "\n" +
+		`{"offset":1,"eve` + "\n" +
+		`{"offset":2,"event":"UserCreated","payload":3}` + "\n"
+	if err := ioutil.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(filename); err == nil {
+		t.Fatal("expected error for corrupted record")
+	}
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != content {
+		t.Error("corrupted log was modified")
+	}
+}
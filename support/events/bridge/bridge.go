@@ -0,0 +1,204 @@
+package bridge
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"sync"
+)
+
+// Message passed through transport
+type Message struct {
+	Source  string          `json:"source"` // bridge that published message
+	Event   string          `json:"event"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+type remoteKey struct{}
+
+// IsRemote checks that event in context was received from transport (only in context mode).
+func IsRemote(ctx context.Context) bool {
+	return remote(ctx) != nil
+}
+
+// received message that is being emitted
+type received struct {
+	event   string
+	payload []byte
+}
+
+func (r *received) is(eventName string, data []byte) bool {
+	return r.event == eventName && bytes.Equal(r.payload, data)
+}
+
+func remote(ctx context.Context) *received {
+	r, _ := ctx.Value(remoteKey{}).(*received)
+	return r
+}
+
+// Bridge connects event bus to the transport: events from mirror or sink are published to the topic and messages from
+// the topic are emitted to the universal JSON emitter (generated by --json). Received event is not published back:
+// only the same event with the same payload is skipped, events emitted by handlers of received one are published.
+type Bridge struct {
+	transport Transport
+	topic     string
+	source    string
+	lock      sync.Mutex
+	lastEcho  uint64
+	echoes    map[string][]uint64 // received messages being emitted by Listen (name + payload) -> expected echoes
+}
+
+// New bridge over transport. All events are published to the single topic.
+func New(transport Transport, topic string) *Bridge {
+	var id [16]byte
+	_, _ = rand.Read(id[:])
+	return &Bridge{
+		transport: transport,
+		topic:     topic,
+		source:    hex.EncodeToString(id[:]),
+		echoes:    make(map[string][]uint64),
+	}
+}
+
+// Source is an unique ID of the bridge that marks published messages
+func (b *Bridge) Source() string {
+	return b.source
+}
+
+// Publish event to the transport. Payload encoded to JSON. Echo of received event (the same event and payload being
+// emitted from the transport) is skipped.
+func (b *Bridge) Publish(ctx context.Context, eventName string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	if r := remote(ctx); r != nil && r.is(eventName, data) {
+		return nil
+	}
+	if b.echo(eventName, data) {
+		return nil
+	}
+	return b.publish(ctx, eventName, data)
+}
+
+// Feed event to the transport. Signature is compatible with mirror and sink (without context) of generated event bus.
+// Errors are logged.
+func (b *Bridge) Feed(eventName string, payload interface{}) {
+	if err := b.Publish(context.Background(), eventName, payload); err != nil {
+		log.Println("failed publish event", eventName, "to topic", b.topic, ":", err)
+	}
+}
+
+// FeedContext is same as Feed but compatible with sink in context mode. Events received from transport are skipped.
+func (b *Bridge) FeedContext(ctx context.Context, eventName string, payload interface{}) {
+	if err := b.Publish(ctx, eventName, payload); err != nil {
+		log.Println("failed publish event", eventName, "to topic", b.topic, ":", err)
+	}
+}
+
+// Listen messages from the transport and emit them through emitter. Emitter signature is compatible with generated
+// EmitJSON (without context). Own messages are ignored. While message is being emitted, the first publishing of the
+// same event with the same payload (mirror called by emitter) is treated as echo and skipped.
+func (b *Bridge) Listen(emit func(eventName string, data []byte) error) (stop func(), err error) {
+	return b.transport.Subscribe(b.topic, func(data []byte) {
+		msg, ok := b.decode(data)
+		if !ok {
+			return
+		}
+		defer b.expect(msg)()
+		if err := emit(msg.Event, msg.Payload); err != nil {
+			log.Println("failed emit event", msg.Event, "from topic", b.topic, ":", err)
+		}
+	})
+}
+
+// ListenContext is same as Listen but compatible with generated EmitJSON in context mode. Context of emitted events
+// is marked as remote (see IsRemote) and keeps received message, so FeedContext skips only its echo.
+func (b *Bridge) ListenContext(ctx context.Context, emit func(ctx context.Context, eventName string, data []byte) error) (stop func(), err error) {
+	return b.transport.Subscribe(b.topic, func(data []byte) {
+		msg, ok := b.decode(data)
+		if !ok {
+			return
+		}
+		msgCtx := context.WithValue(ctx, remoteKey{}, &received{event: msg.Event, payload: compact(msg.Payload)})
+		if err := emit(msgCtx, msg.Event, msg.Payload); err != nil {
+			log.Println("failed emit event", msg.Event, "from topic", b.topic, ":", err)
+		}
+	})
+}
+
+func (b *Bridge) publish(ctx context.Context, eventName string, data []byte) error {
+	msg, err := json.Marshal(Message{
+		Source:  b.source,
+		Event:   eventName,
+		Payload: data,
+	})
+	if err != nil {
+		return err
+	}
+	return b.transport.Publish(ctx, b.topic, msg)
+}
+
+func (b *Bridge) decode(data []byte) (Message, bool) {
+	var msg Message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		log.Println("failed decode message from topic", b.topic, ":", err)
+		return msg, false
+	}
+	return msg, msg.Source != b.source
+}
+
+// expect echo of received message. Returned function removes expectation if echo was not published.
+func (b *Bridge) expect(msg Message) (done func()) {
+	key := msg.Event + "\x00" + string(compact(msg.Payload))
+	b.lock.Lock()
+	b.lastEcho++
+	id := b.lastEcho
+	b.echoes[key] = append(b.echoes[key], id)
+	b.lock.Unlock()
+	return func() {
+		b.lock.Lock()
+		defer b.lock.Unlock()
+		ids := b.echoes[key]
+		for i, echo := range ids {
+			if echo == id {
+				ids = append(ids[:i], ids[i+1:]...)
+				break
+			}
+		}
+		if len(ids) == 0 {
+			delete(b.echoes, key)
+		} else {
+			b.echoes[key] = ids
+		}
+	}
+}
+
+// echo checks that event is expected echo of received message and consumes the expectation
+func (b *Bridge) echo(eventName string, data []byte) bool {
+	key := eventName + "\x00" + string(data)
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	ids := b.echoes[key]
+	if len(ids) == 0 {
+		return false
+	}
+	if len(ids) == 1 {
+		delete(b.echoes, key)
+	} else {
+		b.echoes[key] = ids[1:]
+	}
+	return true
+}
+
+// compact JSON to compare it with encoded payload
+func compact(data []byte) []byte {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return data
+	}
+	return buf.Bytes()
+}
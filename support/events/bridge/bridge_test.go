@@ -0,0 +1,209 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type user struct {
+	Name string `json:"name"`
+}
+
+// bus mimics generated event bus with mirror and EmitJSON
+type bus struct {
+	mirror   func(eventName string, payload interface{})
+	sink     func(ctx context.Context, eventName string, payload interface{})
+	received []user
+}
+
+func (b *bus) Emit(ctx context.Context, payload user) {
+	b.received = append(b.received, payload)
+	if b.mirror != nil {
+		b.mirror("UserCreated", payload)
+	}
+	if b.sink != nil {
+		b.sink(ctx, "UserCreated", payload)
+	}
+}
+
+func (b *bus) EmitJSON(eventName string, data []byte) error {
+	return b.EmitJSONContext(context.Background(), eventName, data)
+}
+
+func (b *bus) EmitJSONContext(ctx context.Context, eventName string, data []byte) error {
+	var payload user
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return err
+	}
+	b.Emit(ctx, payload)
+	return nil
+}
+
+func TestBridge_Mirror(t *testing.T) {
+	transport := NewMemory()
+	var published int
+	stopSpy, _ := transport.Subscribe("events", func(data []byte) { published++ })
+	defer stopSpy()
+
+	var nodes [3]*bus
+	for i := range nodes {
+		b := New(transport, "events")
+		node := &bus{mirror: b.Feed}
+		stop, err := b.Listen(node.EmitJSON)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer stop()
+		nodes[i] = node
+	}
+
+	nodes[0].Emit(context.Background(), user{Name: "reddec"})
+
+	for i, node := range nodes {
+		if len(node.received) != 1 || node.received[0].Name != "reddec" {
+			t.Errorf("node %d received %v", i, node.received)
+		}
+	}
+	if published != 1 {
+		t.Errorf("expected one published message, got %d", published)
+	}
+}
+
+func TestBridge_Context(t *testing.T) {
+	transport := NewMemory()
+	var published int
+	stopSpy, _ := transport.Subscribe("events", func(data []byte) { published++ })
+	defer stopSpy()
+
+	var nodes [2]*bus
+	for i := range nodes {
+		b := New(transport, "events")
+		node := &bus{sink: b.FeedContext}
+		stop, err := b.ListenContext(context.Background(), node.EmitJSONContext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer stop()
+		nodes[i] = node
+	}
+
+	nodes[1].Emit(context.Background(), user{Name: "alice"})
+	nodes[1].Emit(context.Background(), user{Name: "bob"})
+
+	for i, node := range nodes {
+		if len(node.received) != 2 {
+			t.Errorf("node %d received %v", i, node.received)
+		}
+	}
+	if published != 2 {
+		t.Errorf("expected two published messages, got %d", published)
+	}
+}
+
+// spy collects names of published events
+func spy(t *testing.T, transport Transport) (events func() []string, stop func()) {
+	var lock sync.Mutex
+	var published []string
+	stop, err := transport.Subscribe("events", func(data []byte) {
+		var msg Message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			t.Error(err)
+		}
+		lock.Lock()
+		defer lock.Unlock()
+		published = append(published, msg.Event+" "+string(msg.Payload))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return func() []string {
+		lock.Lock()
+		defer lock.Unlock()
+		return append([]string(nil), published...)
+	}, stop
+}
+
+// sameEvents compares events regardless of order (memory transport delivers to subscribers in random order)
+func sameEvents(actual, expected []string) bool {
+	actual = append([]string(nil), actual...)
+	expected = append([]string(nil), expected...)
+	sort.Strings(actual)
+	sort.Strings(expected)
+	return strings.Join(actual, ",") == strings.Join(expected, ",")
+}
+
+func TestBridge_Derived(t *testing.T) {
+	transport := NewMemory()
+	published, stopSpy := spy(t, transport)
+	defer stopSpy()
+
+	remote := New(transport, "events")
+	b := New(transport, "events")
+	stop, err := b.Listen(func(eventName string, data []byte) error {
+		if eventName == "OrderPlaced" {
+			b.Feed(eventName, json.RawMessage(data))                 // mirror of received event
+			b.Feed("InvoiceCreated", map[string]int{"order": 1})     // handler emits derived event
+			b.Feed("OrderPlaced", map[string]int{"id": 2, "qty": 1}) // and another order
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+
+	if err := remote.Publish(context.Background(), "OrderPlaced", map[string]int{"id": 1}); err != nil {
+		t.Fatal(err)
+	}
+	b.Feed("OrderPlaced", map[string]int{"id": 1}) // the same event emitted locally later
+
+	expected := []string{`OrderPlaced {"id":1}`, `InvoiceCreated {"order":1}`, `OrderPlaced {"id":2,"qty":1}`, `OrderPlaced {"id":1}`}
+	if !sameEvents(published(), expected) {
+		t.Errorf("published %v", published())
+	}
+	if len(b.echoes) != 0 {
+		t.Error("expected echoes not cleaned", b.echoes)
+	}
+}
+
+func TestBridge_DerivedContext(t *testing.T) {
+	transport := NewMemory()
+	published, stopSpy := spy(t, transport)
+	defer stopSpy()
+
+	remote := New(transport, "events")
+	b := New(transport, "events")
+	stop, err := b.ListenContext(context.Background(), func(ctx context.Context, eventName string, data []byte) error {
+		if !IsRemote(ctx) {
+			t.Error("context should be marked as remote")
+		}
+		if eventName == "OrderPlaced" {
+			b.FeedContext(ctx, eventName, json.RawMessage(data))             // sink of received event
+			b.FeedContext(ctx, "InvoiceCreated", map[string]int{"order": 1}) // derived event with the same context
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+
+	if err := remote.Publish(context.Background(), "OrderPlaced", map[string]int{"id": 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{`OrderPlaced {"id":1}`, `InvoiceCreated {"order":1}`}
+	if !sameEvents(published(), expected) {
+		t.Errorf("published %v", published())
+	}
+}
+
+func TestIsRemote(t *testing.T) {
+	if IsRemote(context.Background()) {
+		t.Error("local context marked as remote")
+	}
+}
@@ -0,0 +1,59 @@
+package bridge
+
+import (
+	"context"
+	"sync"
+)
+
+// Transport of messages between nodes (ex: RabbitMQ exchange, NATS subject, MQTT topic). Implementation should
+// deliver published message to all subscribers of the topic including publisher itself (bridge ignores own messages).
+type Transport interface {
+	// Publish message to the topic
+	Publish(ctx context.Context, topic string, data []byte) error
+	// Subscribe to all messages of the topic
+	Subscribe(topic string, handler func(data []byte)) (unsubscribe func(), err error)
+}
+
+// In-memory transport. Messages delivered synchronously in the publisher goroutine. Mostly for tests.
+type Memory struct {
+	lock        sync.RWMutex
+	lastID      uint64
+	subscribers map[string]map[uint64]func(data []byte)
+}
+
+// NewMemory creates in-memory transport
+func NewMemory() *Memory {
+	return &Memory{subscribers: make(map[string]map[uint64]func(data []byte))}
+}
+
+func (m *Memory) Publish(ctx context.Context, topic string, data []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.lock.RLock()
+	var handlers = make([]func(data []byte), 0, len(m.subscribers[topic]))
+	for _, handler := range m.subscribers[topic] {
+		handlers = append(handlers, handler)
+	}
+	m.lock.RUnlock()
+	for _, handler := range handlers {
+		handler(append([]byte{}, data...))
+	}
+	return nil
+}
+
+func (m *Memory) Subscribe(topic string, handler func(data []byte)) (unsubscribe func(), err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.lastID++
+	id := m.lastID
+	if m.subscribers[topic] == nil {
+		m.subscribers[topic] = make(map[uint64]func(data []byte))
+	}
+	m.subscribers[topic][id] = handler
+	return func() {
+		m.lock.Lock()
+		delete(m.subscribers[topic], id)
+		m.lock.Unlock()
+	}, nil
+}
@@ -0,0 +1,182 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultSSEHistory   = 128
+	DefaultSSEHeartbeat = 15 * time.Second
+	sseClientBuffer     = 64
+)
+
+type sseEvent struct {
+	id   uint64
+	name string
+	data []byte
+}
+
+type sseClient struct {
+	events chan sseEvent
+	filter map[string]bool // empty means all events
+}
+
+func (cl *sseClient) accept(eventName string) bool {
+	return len(cl.filter) == 0 || cl.filter[eventName]
+}
+
+type sseStreamer struct {
+	heartbeat time.Duration
+	lock      sync.Mutex
+	lastID    uint64
+	history   []sseEvent // ring of last events
+	head      int        // position of the next event in the ring
+	clients   map[*sseClient]bool
+}
+
+// NewSSEStream creates Server-Sent Events streamer. Each event sent as JSON object {"event": name, "payload": payload}
+// in data field with sequential id. Last history events are kept in memory and replayed for reconnected clients by
+// Last-Event-ID header (or lastEventId query param). Clients may filter events by query params: ?event=A&event=B.
+// Heartbeat comments are sent to keep connections alive behind proxies. Clients that can't keep up are disconnected
+// (they can resume by Last-Event-ID). Non-positive history or heartbeat means default values.
+func NewSSEStream(history int, heartbeat time.Duration) *sseStreamer {
+	if history <= 0 {
+		history = DefaultSSEHistory
+	}
+	if heartbeat <= 0 {
+		heartbeat = DefaultSSEHeartbeat
+	}
+	return &sseStreamer{
+		heartbeat: heartbeat,
+		history:   make([]sseEvent, 0, history),
+		clients:   make(map[*sseClient]bool),
+	}
+}
+
+func (ss *sseStreamer) Feed(eventName string, payload interface{}) {
+	data, _ := json.Marshal(event{
+		Name:    eventName,
+		Payload: payload,
+	})
+	ss.lock.Lock()
+	defer ss.lock.Unlock()
+	ss.lastID++
+	ev := sseEvent{id: ss.lastID, name: eventName, data: data}
+	if len(ss.history) < cap(ss.history) {
+		ss.history = append(ss.history, ev)
+	} else {
+		ss.history[ss.head] = ev
+	}
+	ss.head = (ss.head + 1) % cap(ss.history)
+
+	for cl := range ss.clients {
+		if !cl.accept(eventName) {
+			continue
+		}
+		select {
+		case cl.events <- ev:
+		default:
+			// slow client: disconnect, it can resume by Last-Event-ID
+			delete(ss.clients, cl)
+			close(cl.events)
+		}
+	}
+}
+
+func (ss *sseStreamer) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	defer request.Body.Close()
+	flusher, ok := writer.(http.Flusher)
+	if !ok {
+		http.Error(writer, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	cl := &sseClient{
+		events: make(chan sseEvent, sseClientBuffer),
+		filter: make(map[string]bool),
+	}
+	for _, name := range request.URL.Query()["event"] {
+		cl.filter[name] = true
+	}
+	lastEventID := request.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = request.URL.Query().Get("lastEventId")
+	}
+	lastID, _ := strconv.ParseUint(lastEventID, 10, 64)
+
+	writer.Header().Set("Content-Type", "text/event-stream")
+	writer.Header().Set("Cache-Control", "no-cache")
+	writer.Header().Set("Connection", "keep-alive")
+	writer.WriteHeader(http.StatusOK)
+
+	ss.lock.Lock()
+	var replay []sseEvent
+	if lastEventID != "" {
+		replay = ss.since(lastID, cl)
+	}
+	ss.clients[cl] = true
+	ss.lock.Unlock()
+	defer ss.remove(cl)
+
+	for _, ev := range replay {
+		if writeSSE(writer, ev) != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(ss.heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-request.Context().Done():
+			return
+		case ev, ok := <-cl.events:
+			if !ok {
+				return
+			}
+			if writeSSE(writer, ev) != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(writer, ": ping\n\n"); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// since collects events from history after id in order. Should be called under lock.
+func (ss *sseStreamer) since(id uint64, cl *sseClient) []sseEvent {
+	var events []sseEvent
+	start := 0
+	if len(ss.history) == cap(ss.history) {
+		start = ss.head
+	}
+	for i := 0; i < len(ss.history); i++ {
+		ev := ss.history[(start+i)%len(ss.history)]
+		if ev.id > id && cl.accept(ev.name) {
+			events = append(events, ev)
+		}
+	}
+	return events
+}
+
+func (ss *sseStreamer) remove(cl *sseClient) {
+	ss.lock.Lock()
+	defer ss.lock.Unlock()
+	if ss.clients[cl] {
+		delete(ss.clients, cl)
+		close(cl.events)
+	}
+}
+
+func writeSSE(writer http.ResponseWriter, ev sseEvent) error {
+	_, err := fmt.Fprintf(writer, "id: %d\ndata: %s\n\n", ev.id, ev.data)
+	return err
+}
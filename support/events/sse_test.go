@@ -0,0 +1,109 @@
+package events
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func connectSSE(t *testing.T, ss *sseStreamer, url, lastEventID string) (*bufio.Reader, func()) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	ss.lock.Lock()
+	clients := len(ss.clients)
+	ss.lock.Unlock()
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for {
+		ss.lock.Lock()
+		registered := len(ss.clients) > clients
+		ss.lock.Unlock()
+		if registered {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return bufio.NewReader(res.Body), func() { _ = res.Body.Close() }
+}
+
+// readSSE reads lines of the next message (without comments if skipComments)
+func readSSE(t *testing.T, reader *bufio.Reader, skipComments bool) []string {
+	t.Helper()
+	var lines []string
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			if len(lines) > 0 {
+				return lines
+			}
+			continue
+		}
+		if skipComments && strings.HasPrefix(line, ":") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+}
+
+func TestSSEStream_Filter(t *testing.T) {
+	ss := NewSSEStream(0, 0)
+	server := httptest.NewServer(ss)
+	defer server.Close()
+
+	reader, disconnect := connectSSE(t, ss, server.URL+"?event=UserCreated", "")
+	defer disconnect()
+	ss.Feed("UserRemoved", 1)
+	ss.Feed("UserCreated", 2)
+
+	msg := readSSE(t, reader, true)
+	if strings.Join(msg, "|") != `id: 2|data: {"event":"UserCreated","payload":2}` {
+		t.Errorf("unexpected message %v", msg)
+	}
+}
+
+func TestSSEStream_Resume(t *testing.T) {
+	ss := NewSSEStream(3, 0)
+	server := httptest.NewServer(ss)
+	defer server.Close()
+
+	for i := 1; i <= 5; i++ {
+		ss.Feed("Tick", i)
+	}
+	reader, disconnect := connectSSE(t, ss, server.URL, "3")
+	defer disconnect()
+	ss.Feed("Tick", 6)
+	for _, id := range []string{"4", "5", "6"} {
+		msg := readSSE(t, reader, true)
+		if msg[0] != "id: "+id {
+			t.Errorf("expected id %s, got %v", id, msg)
+		}
+	}
+}
+
+func TestSSEStream_Heartbeat(t *testing.T) {
+	ss := NewSSEStream(0, 10*time.Millisecond)
+	server := httptest.NewServer(ss)
+	defer server.Close()
+
+	reader, disconnect := connectSSE(t, ss, server.URL, "")
+	defer disconnect()
+	msg := readSSE(t, reader, false)
+	if msg[0] != ": ping" {
+		t.Errorf("expected heartbeat, got %v", msg)
+	}
+}
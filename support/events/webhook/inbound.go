@@ -0,0 +1,132 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	EventHeader      = "X-Event"
+	SignatureHeader  = "X-Signature"
+	TimestampHeader  = "X-Timestamp" // unix time in seconds
+	DefaultTolerance = 5 * time.Minute
+	DefaultMaxBody   = 1 << 20 // 1 MiB
+	signaturePrefix  = "sha256="
+)
+
+// Sign event by HMAC-SHA256 of event name, timestamp (unix seconds) and body separated by new line. Result is a value
+// of signature header.
+func Sign(secret []byte, eventName string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write([]byte(eventName + "\n" + strconv.FormatInt(timestamp, 10) + "\n"))
+	_, _ = mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify signature of event
+func Verify(secret []byte, eventName string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, eventName, timestamp, body)), []byte(signature))
+}
+
+// Inbound webhook handler: accepts POST /{event} with JSON body and feeds it to the universal emitter with errors of
+// generated event bus (TryEmit, --from-mirror). If secret is set, signature is checked before anything else, so
+// unauthenticated callers can't discover event names. Responses:
+//
+// 204 - event emitted, 404 - unknown event, 400 - invalid JSON or payload rejected by emitter (ex: validation),
+// 401 - invalid signature or timestamp out of tolerance, 405 - not POST, 413 - body too large
+type Inbound struct {
+	Secret    []byte        // optional secret to verify signature (see Sign)
+	Tolerance time.Duration // maximum difference between signature timestamp and local time
+	MaxBody   int64         // limit of body size (0 - unlimited)
+	payload   func(eventName string) interface{}
+	emit      func(ctx context.Context, eventName string, payload interface{}) error
+}
+
+// NewInbound creates webhook handler over generated Payload and TryEmit (without context).
+func NewInbound(payload func(eventName string) interface{}, emit func(eventName string, payload interface{}) error) *Inbound {
+	return NewInboundContext(payload, func(_ context.Context, eventName string, payload interface{}) error {
+		return emit(eventName, payload)
+	})
+}
+
+// NewInboundContext creates webhook handler over generated Payload and TryEmit in context mode. Context of request
+// passed to emitter.
+func NewInboundContext(payload func(eventName string) interface{}, emit func(ctx context.Context, eventName string, payload interface{}) error) *Inbound {
+	return &Inbound{
+		Tolerance: DefaultTolerance,
+		MaxBody:   DefaultMaxBody,
+		payload:   payload,
+		emit:      emit,
+	}
+}
+
+func (in *Inbound) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	defer request.Body.Close()
+	if request.Method != http.MethodPost {
+		writer.Header().Set("Allow", http.MethodPost)
+		http.Error(writer, "only POST allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	eventName := strings.Trim(request.URL.Path, "/")
+	if idx := strings.LastIndex(eventName, "/"); idx >= 0 {
+		eventName = eventName[idx+1:]
+	}
+
+	var reader io.Reader = request.Body
+	if in.MaxBody > 0 {
+		reader = io.LimitReader(request.Body, in.MaxBody+1)
+	}
+	body, err := ioutil.ReadAll(reader)
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if in.MaxBody > 0 && int64(len(body)) > in.MaxBody {
+		http.Error(writer, "body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if len(in.Secret) > 0 && !in.verify(eventName, body, request.Header) {
+		http.Error(writer, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	payload := in.payload(eventName)
+	if payload == nil {
+		http.Error(writer, "unknown event", http.StatusNotFound)
+		return
+	}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, payload); err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if err := in.emit(request.Context(), eventName, payload); err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func (in *Inbound) verify(eventName string, body []byte, header http.Header) bool {
+	timestamp, err := strconv.ParseInt(header.Get(TimestampHeader), 10, 64)
+	if err != nil {
+		return false
+	}
+	diff := time.Since(time.Unix(timestamp, 0))
+	if diff < 0 {
+		diff = -diff
+	}
+	if diff > in.Tolerance {
+		return false
+	}
+	return Verify(in.Secret, eventName, timestamp, body, header.Get(SignatureHeader))
+}
@@ -0,0 +1,189 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultQueueSize = 1024
+	DefaultTimeout   = 10 * time.Second
+)
+
+type delivery struct {
+	eventName string
+	body      []byte
+}
+
+// Outbound webhook sink: POSTs events as JSON to <url>/<event> for each configured URL. Event name is also passed
+// in X-Event header, event is signed in X-Signature and X-Timestamp headers (if secret defined). Network errors, 5xx
+// and 429 responses are retried with exponential backoff.
+//
+// Feed and FeedContext are asynchronous: events are delivered in order by background worker from the bounded queue.
+// Events are dropped (with log message) once queue is full. Send is synchronous.
+type Outbound struct {
+	URLs    []string
+	Secret  []byte        // optional secret to sign events (see Sign)
+	Client  *http.Client  // client with DefaultTimeout by default
+	Retries int           // number of retries after the first attempt
+	Backoff time.Duration // delay before the first retry, doubled for each next retry
+	lock    sync.RWMutex
+	closed  bool
+	queue   chan delivery
+	done    chan struct{}
+	stop    context.CancelFunc
+	ctx     context.Context
+}
+
+// NewOutbound creates webhook sink with 3 retries starting from 1 second and starts background worker with
+// DefaultQueueSize queue. Outbound should be closed to stop the worker.
+func NewOutbound(secret []byte, urls ...string) *Outbound {
+	ctx, stop := context.WithCancel(context.Background())
+	out := &Outbound{
+		URLs:    urls,
+		Secret:  secret,
+		Client:  &http.Client{Timeout: DefaultTimeout},
+		Retries: 3,
+		Backoff: time.Second,
+		queue:   make(chan delivery, DefaultQueueSize),
+		done:    make(chan struct{}),
+		ctx:     ctx,
+		stop:    stop,
+	}
+	go out.run()
+	return out
+}
+
+// Send event to all URLs synchronously. Payload encoded to JSON. Returns the first error after all retries.
+func (out *Outbound) Send(ctx context.Context, eventName string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return out.send(ctx, eventName, body)
+}
+
+// Feed event to the webhooks. Signature is compatible with mirror and sink (without context) of generated event bus.
+// Payload is encoded immediately, delivery is asynchronous. Errors are logged.
+func (out *Outbound) Feed(eventName string, payload interface{}) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		log.Println("failed encode event", eventName, "for webhook:", err)
+		return
+	}
+	out.lock.RLock()
+	defer out.lock.RUnlock()
+	if out.closed {
+		log.Println("webhook closed, event", eventName, "dropped")
+		return
+	}
+	select {
+	case out.queue <- delivery{eventName: eventName, body: body}:
+	default:
+		log.Println("webhook queue is full, event", eventName, "dropped")
+	}
+}
+
+// FeedContext is same as Feed but compatible with sink in context mode. Context is not used for asynchronous delivery.
+func (out *Outbound) FeedContext(_ context.Context, eventName string, payload interface{}) {
+	out.Feed(eventName, payload)
+}
+
+// Close stops accepting new events and waits for delivery of queued events. Pending retries are interrupted once
+// ctx is done.
+func (out *Outbound) Close(ctx context.Context) error {
+	out.lock.Lock()
+	if !out.closed {
+		out.closed = true
+		close(out.queue)
+	}
+	out.lock.Unlock()
+	select {
+	case <-out.done:
+		return nil
+	case <-ctx.Done():
+		out.stop()
+		<-out.done
+		return ctx.Err()
+	}
+}
+
+func (out *Outbound) run() {
+	defer close(out.done)
+	defer out.stop()
+	for item := range out.queue {
+		if err := out.send(out.ctx, item.eventName, item.body); err != nil {
+			log.Println("failed send event", item.eventName, "to webhook:", err)
+		}
+	}
+}
+
+func (out *Outbound) send(ctx context.Context, eventName string, body []byte) error {
+	var firstErr error
+	for _, url := range out.URLs {
+		if err := out.deliver(ctx, strings.TrimSuffix(url, "/")+"/"+eventName, eventName, body); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (out *Outbound) deliver(ctx context.Context, url, eventName string, body []byte) error {
+	backoff := out.Backoff
+	var err error
+	for attempt := 0; attempt <= out.Retries; attempt++ {
+		if attempt > 0 {
+			timer := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+			backoff *= 2
+		}
+		var retry bool
+		retry, err = out.post(ctx, url, eventName, body)
+		if !retry {
+			return err
+		}
+	}
+	return err
+}
+
+func (out *Outbound) post(ctx context.Context, url, eventName string, body []byte) (retry bool, err error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, eventName)
+	if len(out.Secret) > 0 {
+		timestamp := time.Now().Unix()
+		req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+		req.Header.Set(SignatureHeader, Sign(out.Secret, eventName, timestamp, body))
+	}
+	client := out.Client
+	if client == nil {
+		client = &http.Client{Timeout: DefaultTimeout}
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	_ = res.Body.Close()
+	if res.StatusCode/100 == 2 {
+		return false, nil
+	}
+	err = fmt.Errorf("webhook %s returned %s", url, res.Status)
+	return res.StatusCode >= 500 || res.StatusCode == http.StatusTooManyRequests, err
+}
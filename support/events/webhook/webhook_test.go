@@ -0,0 +1,195 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type user struct {
+	Name string `json:"name"`
+}
+
+// bus mimics generated universal Payload and TryEmit
+type bus struct {
+	lock     sync.Mutex
+	received []interface{}
+}
+
+func (b *bus) Payload(eventName string) interface{} {
+	if eventName == "UserCreated" || eventName == "UserRemoved" {
+		return &user{}
+	}
+	return nil
+}
+
+func (b *bus) TryEmit(eventName string, payload interface{}) error {
+	if payload.(*user).Name == "" {
+		return errors.New("name required")
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.received = append(b.received, payload)
+	return nil
+}
+
+func (b *bus) Received() []interface{} {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return append([]interface{}(nil), b.received...)
+}
+
+func TestInbound(t *testing.T) {
+	var b bus
+	handler := NewInbound(b.Payload, b.TryEmit)
+	if handler.MaxBody != DefaultMaxBody {
+		t.Error("body should be limited by default")
+	}
+	handler.Secret = []byte("secret")
+	handler.MaxBody = 64
+
+	now := time.Now().Unix()
+	send := func(method, path, body, signature string) int {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		if signature != "" {
+			req.Header.Set(SignatureHeader, signature)
+			req.Header.Set(TimestampHeader, strconv.FormatInt(now, 10))
+		}
+		res := httptest.NewRecorder()
+		handler.ServeHTTP(res, req)
+		return res.Code
+	}
+	sign := func(eventName, body string) string { return Sign([]byte("secret"), eventName, now, []byte(body)) }
+	expired := Sign([]byte("secret"), "UserCreated", now-3600, []byte(`{}`))
+
+	cases := []struct {
+		method, path, body, signature string
+		code                          int
+	}{
+		{http.MethodGet, "/UserCreated", "", "", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/OrderPlaced", `{}`, sign("OrderPlaced", `{}`), http.StatusNotFound},
+		{http.MethodPost, "/OrderPlaced", `{}`, "", http.StatusUnauthorized},                      // event names are not disclosed
+		{http.MethodPost, "/UserCreated", `{}`, sign("UserCreated", `{}`), http.StatusBadRequest}, // rejected by emitter
+		{http.MethodPost, "/UserCreated", `{"name":"x"}`, "sha256=00", http.StatusUnauthorized},
+		{http.MethodPost, "/UserRemoved", `{"name":"x"}`, sign("UserCreated", `{"name":"x"}`), http.StatusUnauthorized},
+		{http.MethodPost, "/UserCreated", `{}`, expired, http.StatusUnauthorized},
+		{http.MethodPost, "/UserCreated", `{"name":`, sign("UserCreated", `{"name":`), http.StatusBadRequest},
+		{http.MethodPost, "/UserCreated", `{"name":"` + string(bytes.Repeat([]byte("x"), 64)) + `"}`, "", http.StatusRequestEntityTooLarge},
+		{http.MethodPost, "/hooks/UserCreated", `{"name":"reddec"}`, sign("UserCreated", `{"name":"reddec"}`), http.StatusNoContent},
+	}
+	for _, c := range cases {
+		if code := send(c.method, c.path, c.body, c.signature); code != c.code {
+			t.Errorf("%s %s: expected %d, got %d", c.method, c.path, c.code, code)
+		}
+	}
+	if received := b.Received(); len(received) != 1 || received[0].(*user).Name != "reddec" {
+		t.Errorf("received %v", received)
+	}
+}
+
+func TestOutbound(t *testing.T) {
+	var b bus
+	inbound := NewInbound(b.Payload, b.TryEmit)
+	inbound.Secret = []byte("secret")
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if request.Header.Get(EventHeader) != "UserCreated" {
+			t.Error("no event header")
+		}
+		inbound.ServeHTTP(writer, request)
+	}))
+	defer server.Close()
+
+	out := NewOutbound([]byte("secret"), server.URL+"/hooks/")
+	defer out.Close(context.Background())
+	out.Backoff = time.Millisecond
+	if err := out.Send(context.Background(), "UserCreated", user{Name: "reddec"}); err != nil {
+		t.Fatal(err)
+	}
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts)
+	}
+	if received := b.Received(); len(received) != 1 || received[0].(*user).Name != "reddec" {
+		t.Errorf("received %v", received)
+	}
+}
+
+func TestOutbound_Fail(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		writer.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	out := NewOutbound(nil, server.URL)
+	defer out.Close(context.Background())
+	out.Backoff = time.Millisecond
+	if err := out.Send(context.Background(), "UserCreated", user{}); err == nil {
+		t.Error("error expected")
+	}
+	if attempts != 1 {
+		t.Errorf("client errors should not be retried, got %d attempts", attempts)
+	}
+}
+
+func TestOutbound_Feed(t *testing.T) {
+	var b bus
+	inbound := NewInbound(b.Payload, b.TryEmit)
+	inbound.Secret = []byte("secret")
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		<-release // hung endpoint
+		inbound.ServeHTTP(writer, request)
+	}))
+	defer server.Close()
+
+	out := NewOutbound([]byte("secret"), server.URL)
+	fed := make(chan struct{})
+	go func() {
+		defer close(fed)
+		out.Feed("UserCreated", user{Name: "alice"})
+		out.Feed("UserRemoved", user{Name: "bob"})
+	}()
+	select {
+	case <-fed:
+	case <-time.After(time.Second):
+		t.Fatal("feed blocked by endpoint")
+	}
+	close(release)
+	if err := out.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	out.Feed("UserCreated", user{}) // dropped after close
+	received := b.Received()
+	if len(received) != 2 || received[0].(*user).Name != "alice" || received[1].(*user).Name != "bob" {
+		t.Errorf("received %v", received)
+	}
+}
+
+func TestOutbound_CloseTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	out := NewOutbound(nil, server.URL)
+	out.Backoff = time.Hour
+	out.Feed("UserCreated", user{})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := out.Close(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expected deadline, got %v", err)
+	}
+}
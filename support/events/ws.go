@@ -4,20 +4,108 @@ import (
 	"encoding/json"
 	"github.com/gorilla/websocket"
 	"net/http"
+	"path"
 	"sync"
+	"time"
+)
+
+const (
+	wsQueueSize    = 64
+	wsWriteTimeout = 10 * time.Second
 )
 
 type event struct {
 	Name    string      `json:"event"`
 	Payload interface{} `json:"payload"`
 }
+
+// Control message from client. Events are event names or patterns (see path.Match). Until the first control message
+// client receives all events.
+type Control struct {
+	Action string   `json:"action"` // subscribe or unsubscribe
+	Events []string `json:"events"`
+}
+
+type wsClient struct {
+	info      Connection
+	conn      *websocket.Conn
+	queue     chan []byte
+	closeCode int             // sent to client once queue closed
+	filtered  bool            // client sent control message
+	patterns  map[string]bool // subscribed events and patterns
+}
+
+func (cl *wsClient) accept(eventName string) bool {
+	if cl.info.Allowed != nil && !matchEvent(cl.info.Allowed, eventName) {
+		return false
+	}
+	if !cl.filtered || cl.patterns[eventName] {
+		return true
+	}
+	for pattern := range cl.patterns {
+		if ok, _ := path.Match(pattern, eventName); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func matchEvent(patterns []string, eventName string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, eventName); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (cl *wsClient) control(msg Control) {
+	switch msg.Action {
+	case "subscribe":
+		for _, pattern := range msg.Events {
+			cl.patterns[pattern] = true
+		}
+	case "unsubscribe":
+		for _, pattern := range msg.Events {
+			delete(cl.patterns, pattern)
+		}
+	default:
+		return
+	}
+	cl.filtered = true
+}
+
 type wsStreamer struct {
-	feedLock sync.Mutex
-	writers  []*websocket.Conn
+	authorize   Authorizer
+	checkOrigin func(request *http.Request) bool
+	metadata    func(request *http.Request) interface{}
+	lock        sync.Mutex
+	clients     map[*wsClient]bool
+	closed      bool
+	writers     sync.WaitGroup
 }
 
-func NewWebsocketStream() *wsStreamer {
-	return &wsStreamer{}
+// NewWebsocketStream creates websocket streamer. Each connection has own send queue: Feed never blocks and clients
+// that can't keep up (queue overflow) or failed writes are disconnected.
+func NewWebsocketStream(options ...WebsocketOption) *wsStreamer {
+	ws := &wsStreamer{
+		clients: make(map[*wsClient]bool),
+	}
+	for _, option := range options {
+		option(ws)
+	}
+	return ws
+}
+
+// Connections returns snapshot of active connections
+func (ws *wsStreamer) Connections() []Connection {
+	ws.lock.Lock()
+	defer ws.lock.Unlock()
+	var list = make([]Connection, 0, len(ws.clients))
+	for cl := range ws.clients {
+		list = append(list, cl.info)
+	}
+	return list
 }
 
 func (ws *wsStreamer) Feed(eventName string, payload interface{}) {
@@ -25,53 +113,130 @@ func (ws *wsStreamer) Feed(eventName string, payload interface{}) {
 		Name:    eventName,
 		Payload: payload,
 	})
-	var wg sync.WaitGroup
-	ws.feedLock.Lock()
-	defer ws.feedLock.Unlock()
-	for _, conn := range ws.writers {
-		wg.Add(1)
-		go func(conn *websocket.Conn) {
-			defer wg.Done()
-			err := conn.WriteMessage(websocket.TextMessage, data)
-			if err != nil {
-				_ = conn.Close()
-			}
-		}(conn)
-	}
-	wg.Wait()
+	ws.lock.Lock()
+	defer ws.lock.Unlock()
+	for cl := range ws.clients {
+		if !cl.accept(eventName) {
+			continue
+		}
+		select {
+		case cl.queue <- data:
+		default:
+			ws.evict(cl, websocket.CloseTryAgainLater)
+		}
+	}
+}
+
+// Close all connections gracefully and wait for them. New connections will be rejected.
+func (ws *wsStreamer) Close() {
+	ws.lock.Lock()
+	ws.closed = true
+	for cl := range ws.clients {
+		ws.evict(cl, websocket.CloseGoingAway)
+	}
+	ws.lock.Unlock()
+	ws.writers.Wait()
 }
 
 func (ws *wsStreamer) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
 	upgrader := websocket.Upgrader{
 		ReadBufferSize:  8192,
 		WriteBufferSize: 8192,
+		CheckOrigin:     ws.checkOrigin,
 	}
 	defer request.Body.Close()
 
+	ws.lock.Lock()
+	closed := ws.closed
+	ws.lock.Unlock()
+	if closed {
+		http.Error(writer, "stream closed", http.StatusServiceUnavailable)
+		return
+	}
+
+	info := Connection{
+		RemoteAddr: request.RemoteAddr,
+		Connected:  time.Now(),
+	}
+	if ws.authorize != nil {
+		allowed, err := ws.authorize(request)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		info.Allowed = allowed
+	}
+	if ws.metadata != nil {
+		info.Metadata = ws.metadata(request)
+	}
+
 	conn, err := upgrader.Upgrade(writer, request, nil)
 	if err != nil {
 		return
 	}
 	defer conn.Close()
 
-	ws.feedLock.Lock()
-	ws.writers = append(ws.writers, conn)
-	ws.feedLock.Unlock()
+	cl := &wsClient{
+		info:     info,
+		conn:     conn,
+		queue:    make(chan []byte, wsQueueSize),
+		patterns: make(map[string]bool),
+	}
+	ws.lock.Lock()
+	if ws.closed {
+		ws.lock.Unlock()
+		return
+	}
+	ws.clients[cl] = true
+	ws.writers.Add(1)
+	ws.lock.Unlock()
+
+	go ws.write(cl)
 
 	for {
-		_, _, err := conn.ReadMessage()
+		_, data, err := conn.ReadMessage()
 		if err != nil {
 			break
 		}
+		var msg Control
+		if json.Unmarshal(data, &msg) != nil {
+			continue // ignore malformed control messages
+		}
+		ws.lock.Lock()
+		cl.control(msg)
+		ws.lock.Unlock()
 	}
-	ws.feedLock.Lock()
-	for i, w := range ws.writers {
-		if w == conn {
-			N := len(ws.writers)
-			ws.writers[i] = ws.writers[N-1]
-			ws.writers = ws.writers[:N-1]
-			break
+	ws.remove(cl, websocket.CloseNormalClosure)
+}
+
+func (ws *wsStreamer) write(cl *wsClient) {
+	defer ws.writers.Done()
+	defer cl.conn.Close()
+	for data := range cl.queue {
+		_ = cl.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+		if err := cl.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+			ws.remove(cl, websocket.CloseAbnormalClosure)
+			return
 		}
 	}
-	ws.feedLock.Unlock()
+	ws.lock.Lock()
+	code := cl.closeCode
+	ws.lock.Unlock()
+	_ = cl.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, ""), time.Now().Add(wsWriteTimeout))
+}
+
+func (ws *wsStreamer) remove(cl *wsClient, code int) {
+	ws.lock.Lock()
+	defer ws.lock.Unlock()
+	ws.evict(cl, code)
+}
+
+// evict client: writer sends close message and closes connection. Should be called under lock.
+func (ws *wsStreamer) evict(cl *wsClient, code int) {
+	if !ws.clients[cl] {
+		return
+	}
+	delete(ws.clients, cl)
+	cl.closeCode = code
+	close(cl.queue)
 }
@@ -0,0 +1,62 @@
+package events
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// WebsocketOption customizes websocket streamer
+type WebsocketOption func(ws *wsStreamer)
+
+// Authorizer checks request before upgrade and returns allowed event names or patterns (see path.Match). Nil means all
+// events. Error rejects connection with 401 status.
+type Authorizer func(request *http.Request) (allowed []string, err error)
+
+// Connection describes an active websocket client
+type Connection struct {
+	RemoteAddr string
+	Connected  time.Time
+	Allowed    []string    // allowed events and patterns, nil means all events
+	Metadata   interface{} // see WithMetadata
+}
+
+// WithAuthorization sets authorization callback. Client subscriptions are limited by allowed events.
+func WithAuthorization(authorize Authorizer) WebsocketOption {
+	return func(ws *wsStreamer) {
+		ws.authorize = authorize
+	}
+}
+
+// WithOriginCheck sets custom origin policy. By default only same origin (or no Origin header) is allowed.
+func WithOriginCheck(check func(request *http.Request) bool) WebsocketOption {
+	return func(ws *wsStreamer) {
+		ws.checkOrigin = check
+	}
+}
+
+// WithOrigins allows requests without Origin header or with one of origins (scheme://host[:port]). Origin * allows all.
+func WithOrigins(origins ...string) WebsocketOption {
+	allowed := make(map[string]bool, len(origins))
+	for _, origin := range origins {
+		allowed[origin] = true
+	}
+	return WithOriginCheck(func(request *http.Request) bool {
+		origin := request.Header.Get("Origin")
+		if origin == "" || allowed["*"] {
+			return true
+		}
+		u, err := url.Parse(origin)
+		if err != nil {
+			return false
+		}
+		return allowed[u.Scheme+"://"+u.Host]
+	})
+}
+
+// WithMetadata attaches custom metadata to each connection (see Connections). Called after authorization.
+func WithMetadata(metadata func(request *http.Request) interface{}) WebsocketOption {
+	return func(ws *wsStreamer) {
+		ws.metadata = metadata
+	}
+}
@@ -0,0 +1,203 @@
+package events
+
+import (
+	"errors"
+	"github.com/gorilla/websocket"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func connectWS(t *testing.T, ws *wsStreamer, url string) *websocket.Conn {
+	t.Helper()
+	ws.lock.Lock()
+	clients := len(ws.clients)
+	ws.lock.Unlock()
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(url, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for {
+		ws.lock.Lock()
+		registered := len(ws.clients) > clients
+		ws.lock.Unlock()
+		if registered {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return conn
+}
+
+func TestWebsocketStream_Feed(t *testing.T) {
+	ws := NewWebsocketStream()
+	server := httptest.NewServer(ws)
+	defer server.Close()
+	defer ws.Close()
+
+	clients := []*websocket.Conn{connectWS(t, ws, server.URL), connectWS(t, ws, server.URL)}
+	ws.Feed("UserCreated", 1)
+	for i, conn := range clients {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != `{"event":"UserCreated","payload":1}` {
+			t.Errorf("client %d received %s", i, data)
+		}
+	}
+
+	_ = clients[0].Close()
+	for {
+		ws.lock.Lock()
+		n := len(ws.clients)
+		ws.lock.Unlock()
+		if n == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestWebsocketStream_Evict(t *testing.T) {
+	ws := NewWebsocketStream()
+	slow := &wsClient{queue: make(chan []byte, 1)}
+	ws.clients[slow] = true
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ws.Feed("Tick", 1)
+		ws.Feed("Tick", 2) // queue overflow
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("feed blocked")
+	}
+	if ws.clients[slow] {
+		t.Error("slow client not evicted")
+	}
+	if slow.closeCode != websocket.CloseTryAgainLater {
+		t.Errorf("unexpected close code %d", slow.closeCode)
+	}
+}
+
+func TestWebsocketStream_Close(t *testing.T) {
+	ws := NewWebsocketStream()
+	server := httptest.NewServer(ws)
+	defer server.Close()
+
+	conn := connectWS(t, ws, server.URL)
+	ws.Close()
+	_, _, err := conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+		t.Errorf("expected going away, got %v", err)
+	}
+	if _, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil); err == nil {
+		t.Error("connection to closed stream accepted")
+	}
+}
+
+func TestWebsocketStream_Subscribe(t *testing.T) {
+	ws := NewWebsocketStream()
+	server := httptest.NewServer(ws)
+	defer server.Close()
+	defer ws.Close()
+
+	conn := connectWS(t, ws, server.URL)
+	control := func(msg Control, patterns int) {
+		if err := conn.WriteJSON(msg); err != nil {
+			t.Fatal(err)
+		}
+		if err := conn.WriteMessage(websocket.TextMessage, []byte("{")); err != nil { // malformed message is ignored
+			t.Fatal(err)
+		}
+		for { // wait for processing
+			ws.lock.Lock()
+			var processed bool
+			for cl := range ws.clients {
+				processed = cl.filtered && len(cl.patterns) == patterns
+			}
+			ws.lock.Unlock()
+			if processed {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	expect := func(eventName string) {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), `"event":"`+eventName+`"`) {
+			t.Errorf("expected %s, got %s", eventName, data)
+		}
+	}
+
+	control(Control{Action: "subscribe", Events: []string{"User*"}}, 1)
+	ws.Feed("OrderPlaced", 1)
+	ws.Feed("UserCreated", 2)
+	expect("UserCreated")
+
+	control(Control{Action: "unsubscribe", Events: []string{"User*"}}, 0)
+	control(Control{Action: "subscribe", Events: []string{"OrderPlaced"}}, 1)
+	ws.Feed("UserCreated", 3)
+	ws.Feed("OrderPlaced", 4)
+	expect("OrderPlaced")
+}
+
+func TestWebsocketStream_Options(t *testing.T) {
+	ws := NewWebsocketStream(
+		WithAuthorization(func(request *http.Request) ([]string, error) {
+			if request.URL.Query().Get("token") != "secret" {
+				return nil, errors.New("invalid token")
+			}
+			return []string{"User*"}, nil
+		}),
+		WithOrigins("https://example.com"),
+		WithMetadata(func(request *http.Request) interface{} {
+			return request.Header.Get("X-User")
+		}),
+	)
+	server := httptest.NewServer(ws)
+	defer server.Close()
+	defer ws.Close()
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	if _, res, err := websocket.DefaultDialer.Dial(wsURL, nil); err == nil || res.StatusCode != http.StatusUnauthorized {
+		t.Error("unauthorized connection accepted")
+	}
+	header := http.Header{"Origin": {"https://evil.com"}}
+	if _, res, err := websocket.DefaultDialer.Dial(wsURL+"?token=secret", header); err == nil || res.StatusCode != http.StatusForbidden {
+		t.Error("connection from foreign origin accepted")
+	}
+
+	header = http.Header{"Origin": {"https://example.com"}, "X-User": {"reddec"}}
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL+"?token=secret", header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	var connections []Connection
+	for len(connections) == 0 {
+		connections = ws.Connections()
+		time.Sleep(time.Millisecond)
+	}
+	if connections[0].Metadata != "reddec" || len(connections[0].Allowed) != 1 {
+		t.Errorf("unexpected connection %+v", connections[0])
+	}
+
+	ws.Feed("OrderPlaced", 1) // not allowed
+	ws.Feed("UserCreated", 2)
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"event":"UserCreated","payload":2}` {
+		t.Errorf("received %s", data)
+	}
+}
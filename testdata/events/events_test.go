@@ -0,0 +1,515 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// collector of handled events
+type collector struct {
+	lock   sync.Mutex
+	events []string
+}
+
+func (c *collector) Handle(_ context.Context, payload event) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.events = append(c.events, payload.Name)
+}
+
+func (c *collector) Events() []string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]string(nil), c.events...)
+}
+
+func handlers(ev *xxxx) int {
+	ev.lock.RLock()
+	defer ev.lock.RUnlock()
+	return len(ev.handlers)
+}
+
+func TestChan_DetachOnCancel(t *testing.T) {
+	var bus Events
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := bus.xxxx.Chan(ctx, 1)
+	bus.xxxx.Emit(ctx, event{Name: "first"})
+	bus.xxxx.Emit(ctx, event{Name: "dropped"})
+	if payload := <-ch; payload.Name != "first" {
+		t.Error("unexpected payload", payload)
+	}
+	cancel()
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Error("payload should be dropped by full buffer")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("channel not closed after cancel")
+	}
+	if n := handlers(&bus.xxxx); n != 0 {
+		t.Error("handler not detached:", n)
+	}
+}
+
+func TestChild_Close(t *testing.T) {
+	var bus Events
+	var root, scoped collector
+	bus.xxxx.Subscribe(root.Handle)
+	child := bus.Child()
+	child.xxxx.Subscribe(scoped.Handle)
+	child.xxxx.Emit(context.Background(), event{Name: "first"})
+	child.Close()
+	bus.xxxx.Emit(context.Background(), event{Name: "second"})
+	if events := root.Events(); len(events) != 2 {
+		t.Error("root bus should receive all events:", events)
+	}
+	if events := scoped.Events(); len(events) != 1 || events[0] != "first" {
+		t.Error("closed child should not receive events:", events)
+	}
+	if n := handlers(&bus.xxxx); n != 1 {
+		t.Error("expected only root handler, got", n)
+	}
+}
+
+func TestEmit_Invalid(t *testing.T) {
+	var bus Events
+	var handled int
+	bus.submitted.Subscribe(func(ctx context.Context, payload form) {
+		handled++
+	})
+	if err := bus.submitted.Emit(context.Background(), form{}); err == nil {
+		t.Error("expected validation error")
+	}
+	if err := bus.submitted.Emit(context.Background(), form{Name: "valid"}); err != nil {
+		t.Error(err)
+	}
+	if handled != 1 {
+		t.Error("invalid payload should not be dispatched, handled", handled)
+	}
+}
+
+func TestRequest(t *testing.T) {
+	var bus Events
+	ctx := context.Background()
+	if _, err := bus.ask.Request(ctx, query{Limit: 1}); !errors.Is(err, ErrEventsNoResponder) {
+		t.Error("expected no responder, got", err)
+	}
+	if _, err := bus.ask.Request(ctx, query{Limit: -1}); err == nil || errors.Is(err, ErrEventsNoResponder) {
+		t.Error("expected validation error, got", err)
+	}
+
+	err := bus.ask.Respond(func(ctx context.Context, q query) (eventIDs, error) {
+		if q.Limit == 0 {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return make(eventIDs, q.Limit), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bus.ask.Respond(func(ctx context.Context, q query) (eventIDs, error) { return nil, nil }); !errors.Is(err, ErrEventsResponderExists) {
+		t.Error("expected responder exists, got", err)
+	}
+	if ids, err := bus.ask.Request(ctx, query{Limit: 2}); err != nil || len(ids) != 2 {
+		t.Error("unexpected reply", ids, err)
+	}
+
+	timeout, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := bus.ask.Request(timeout, query{}); err != context.DeadlineExceeded {
+		t.Error("expected deadline, got", err)
+	}
+
+	child := bus.Child()
+	if err := child.ask.Respond(func(ctx context.Context, q query) (eventIDs, error) { return nil, nil }); !errors.Is(err, ErrEventsResponderExists) {
+		t.Error("child should share responder of root, got", err)
+	}
+	child.ask.StopResponding()
+	if _, err := bus.ask.Request(ctx, query{Limit: 1}); err != nil {
+		t.Error("child should not remove responder of root:", err)
+	}
+	bus.ask.StopResponding()
+	if _, err := child.ask.Request(ctx, query{Limit: 1}); !errors.Is(err, ErrEventsNoResponder) {
+		t.Error("expected no responder, got", err)
+	}
+}
+
+func TestMirrors(t *testing.T) {
+	var bus Events
+	var lock sync.Mutex
+	var mirrored []string
+	mirror := func(prefix string) func(envelope EventsEnvelope) {
+		return func(envelope EventsEnvelope) {
+			lock.Lock()
+			defer lock.Unlock()
+			mirrored = append(mirrored, prefix+envelope.Event+":"+envelope.Payload.(event).Name)
+		}
+	}
+	first := bus.AddMirror(mirror("a/"))
+	bus.AddMirror(mirror("b/"))
+	bus.xxxx.Emit(context.Background(), event{Name: "1"})
+	bus.RemoveMirror(first)
+	bus.RemoveMirror(first) // unknown ID is ignored
+	bus.yyy.Emit(context.Background(), event{Name: "2"})
+	expected := []string{"a/xxxx:1", "b/xxxx:1", "b/yyy:2"}
+	if len(mirrored) != len(expected) {
+		t.Fatal(mirrored)
+	}
+	for i := range expected {
+		if mirrored[i] != expected[i] {
+			t.Error(mirrored[i], "!=", expected[i])
+		}
+	}
+}
+
+func TestSubscribeDebounced(t *testing.T) {
+	var bus Events
+	var handled collector
+	stop := bus.xxxx.SubscribeDebounced(50*time.Millisecond, handled.Handle)
+	defer stop()
+	for _, name := range []string{"1", "2", "3"} {
+		bus.xxxx.Emit(context.Background(), event{Name: name})
+	}
+	if events := handled.Events(); len(events) != 0 {
+		t.Error("debounced events delivered before silence:", events)
+	}
+	time.Sleep(200 * time.Millisecond)
+	if events := handled.Events(); len(events) != 1 || events[0] != "3" {
+		t.Error("expected only the last event, got", events)
+	}
+}
+
+func TestSubscribeThrottled(t *testing.T) {
+	var bus Events
+	var handled collector
+	stop := bus.xxxx.SubscribeThrottled(50*time.Millisecond, handled.Handle)
+	for _, name := range []string{"1", "2", "3"} {
+		bus.xxxx.Emit(context.Background(), event{Name: name})
+	}
+	if events := handled.Events(); len(events) != 1 || events[0] != "1" {
+		t.Error("expected the first event immediately, got", events)
+	}
+	time.Sleep(200 * time.Millisecond)
+	if events := handled.Events(); len(events) != 2 || events[1] != "3" {
+		t.Error("expected the last event at the end of interval, got", events)
+	}
+	stop()
+	bus.xxxx.Emit(context.Background(), event{Name: "4"})
+	if n := handlers(&bus.xxxx); n != 0 {
+		t.Error("handler not detached:", n)
+	}
+}
+
+func TestSubscribeThrottled_Serialized(t *testing.T) {
+	var bus Events
+	var active, overlapped int32
+	var handled collector
+	stop := bus.xxxx.SubscribeThrottled(10*time.Millisecond, func(ctx context.Context, payload event) {
+		if atomic.AddInt32(&active, 1) > 1 {
+			atomic.StoreInt32(&overlapped, 1)
+		}
+		time.Sleep(50 * time.Millisecond) // slower than interval
+		handled.Handle(ctx, payload)
+		atomic.AddInt32(&active, -1)
+	})
+	defer stop()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		bus.xxxx.Emit(context.Background(), event{Name: "1"})
+	}()
+	time.Sleep(5 * time.Millisecond)
+	bus.xxxx.Emit(context.Background(), event{Name: "2"})
+	<-done
+	time.Sleep(150 * time.Millisecond)
+	if atomic.LoadInt32(&overlapped) != 0 {
+		t.Error("handler called concurrently")
+	}
+	if events := handled.Events(); len(events) != 2 || events[0] != "1" || events[1] != "2" {
+		t.Error("unexpected events", events)
+	}
+}
+
+func TestTracer(t *testing.T) {
+	var bus Events
+	var tracer EventsMemoryTracer
+	bus.SetTracer(&tracer)
+	bus.xxxx.Subscribe(func(ctx context.Context, payload event) {})
+	bus.xxxx.Subscribe(func(ctx context.Context, payload event) {
+		if payload.Name == "fail" {
+			panic("broken handler")
+		}
+	})
+
+	bus.xxxx.Emit(context.Background(), event{Name: "first"})
+	spans := tracer.Spans()
+	expected := []string{"emit xxxx", "handle xxxx", "handle xxxx"}
+	if len(spans) != len(expected) {
+		t.Fatal("unexpected spans", spans)
+	}
+	for i, span := range spans {
+		if span.Name != expected[i] {
+			t.Error(span.Name, "!=", expected[i])
+		}
+		if !span.Ended {
+			t.Error("span", span.Name, "not ended")
+		}
+		if span.Err != nil {
+			t.Error("span", span.Name, "has error", span.Err)
+		}
+	}
+	for _, span := range spans[1:] {
+		if span.Parent != spans[0].Name {
+			t.Error("handler span should be child of emit span, parent", span.Parent)
+		}
+	}
+
+	tracer.Reset()
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("panic should be propagated")
+			}
+		}()
+		bus.xxxx.Emit(context.Background(), event{Name: "fail"})
+	}()
+	spans = tracer.Spans()
+	if len(spans) != 3 {
+		t.Fatal("unexpected spans", spans)
+	}
+	if failed := spans[2]; failed.Err == nil || !failed.Ended {
+		t.Error("panic should be recorded in ended handler span", failed)
+	}
+	if emit := spans[0]; !emit.Ended {
+		t.Error("emit span should be ended after panic")
+	}
+}
+
+func TestSubscribePattern(t *testing.T) {
+	emit := func(bus *Events) {
+		ctx := context.Background()
+		bus.User.Emit(ctx, entity{ID: 1})
+		bus.UserCreated.Emit(ctx, entity{ID: 2})
+		bus.UserRemoved.Emit(ctx, entity{ID: 3})
+		bus.OrderRemoved.Emit(ctx, entity{ID: 4})
+	}
+	cases := map[string]string{
+		"user.#":    "User,UserCreated,UserRemoved", // # matches zero or more levels
+		"user.*":    "UserCreated,UserRemoved",      // * matches exactly one level
+		"User":      "User",                         // without dot pattern is a glob over event name
+		"user":      "",                             // glob is case sensitive
+		"#.removed": "UserRemoved,OrderRemoved",
+		"*.created": "UserCreated",
+		"User*":     "User,UserCreated,UserRemoved",
+		"#":         "User,UserCreated,UserRemoved,OrderRemoved,xxxx,yyy,submitted,ids,count,ask,traced",
+	}
+	for pattern, expected := range cases {
+		var bus Events
+		var matched []string
+		bus.SubscribePattern(pattern, func(ctx context.Context, envelope EventsEnvelope) {
+			matched = append(matched, envelope.Event)
+		})
+		emit(&bus)
+		ctx := context.Background()
+		bus.xxxx.Emit(ctx, event{})
+		bus.yyy.Emit(ctx, event{})
+		_ = bus.submitted.Emit(ctx, form{Name: "valid"})
+		bus.ids.Emit(ctx, nil)
+		bus.count.Emit(ctx, 1)
+		_ = bus.ask.Emit(ctx, query{})
+		bus.traced.Emit(ctx, Span{})
+		if got := strings.Join(matched, ","); got != expected {
+			t.Errorf("%s: expected %q, got %q", pattern, expected, got)
+		}
+	}
+
+	var bus Events
+	var matched int
+	unsubscribe := bus.SubscribePattern("user.#", func(ctx context.Context, envelope EventsEnvelope) {
+		matched++
+	})
+	emit(&bus)
+	unsubscribe()
+	emit(&bus)
+	if matched != 3 {
+		t.Error("events after unsubscribe should not be delivered, matched", matched)
+	}
+	if n := len(bus.User.handlers) + len(bus.UserCreated.handlers) + len(bus.UserRemoved.handlers); n != 0 {
+		t.Error("pattern handlers not detached:", n)
+	}
+}
+
+func TestEnvelope_Causation(t *testing.T) {
+	var bus Events
+	var envelopes []EventsEnvelope
+	bus.AddMirror(func(envelope EventsEnvelope) {
+		envelopes = append(envelopes, envelope)
+	})
+	bus.xxxx.Subscribe(func(ctx context.Context, payload event) {
+		bus.yyy.Emit(ctx, payload) // derived event
+	})
+
+	ctx := EventsContextWithSource(context.Background(), "api")
+	bus.xxxx.Emit(ctx, event{Name: "first"})
+	bus.xxxx.Emit(EventsContextWithCorrelationID(ctx, "order-1"), event{Name: "second"})
+	if len(envelopes) != 4 {
+		t.Fatal("unexpected envelopes", envelopes)
+	}
+	// mirror called after handlers, so derived event is mirrored first
+	for _, pair := range [][2]EventsEnvelope{{envelopes[1], envelopes[0]}, {envelopes[3], envelopes[2]}} {
+		cause, derived := pair[0], pair[1]
+		if cause.Event != "xxxx" || derived.Event != "yyy" {
+			t.Fatal("unexpected order", cause.Event, derived.Event)
+		}
+		if cause.ID == "" || cause.CausationID != "" {
+			t.Error("root event should have only ID", cause)
+		}
+		if derived.CausationID != cause.ID {
+			t.Error("derived event should be caused by", cause.ID, "got", derived.CausationID)
+		}
+		if cause.Source != "api" || derived.Source != "api" {
+			t.Error("source not propagated", cause.Source, derived.Source)
+		}
+	}
+	if first, derived := envelopes[1], envelopes[0]; first.CorrelationID != "" || derived.CorrelationID != first.ID {
+		t.Error("derived event should be correlated by ID of root event, got", derived.CorrelationID)
+	}
+	if second, derived := envelopes[3], envelopes[2]; second.CorrelationID != "order-1" || derived.CorrelationID != "order-1" {
+		t.Error("correlation ID not propagated", second.CorrelationID, derived.CorrelationID)
+	}
+}
+
+func TestEmitJSON(t *testing.T) {
+	var bus Events
+	var submitted []string
+	var counted []counter
+	bus.submitted.Subscribe(func(ctx context.Context, payload form) {
+		submitted = append(submitted, payload.Name)
+	})
+	bus.count.Subscribe(func(ctx context.Context, payload counter) {
+		counted = append(counted, payload)
+	})
+	ctx := context.Background()
+
+	if err := bus.EmitJSON(ctx, "unknown", []byte(`{}`)); !errors.Is(err, ErrEventsUnknownEvent) {
+		t.Error("expected unknown event, got", err)
+	}
+	if err := bus.EmitJSON(ctx, "submitted", []byte(`{"Name":`)); err == nil || errors.Is(err, ErrEventsUnknownEvent) {
+		t.Error("expected decode error, got", err)
+	}
+	if err := bus.EmitJSON(ctx, "submitted", []byte(`{"Name":""}`)); err == nil {
+		t.Error("expected validation error")
+	}
+	if err := bus.EmitJSON(ctx, "submitted", []byte(`{"Name":"valid"}`)); err != nil {
+		t.Error(err)
+	}
+	if err := bus.EmitJSON(ctx, "count", []byte(`5`)); err != nil {
+		t.Error(err)
+	}
+	if err := bus.TryEmit(ctx, "count", "5"); !errors.Is(err, ErrEventsPayloadType) {
+		t.Error("expected payload type error, got", err)
+	}
+	if len(submitted) != 1 || submitted[0] != "valid" {
+		t.Error("only valid payload should be dispatched:", submitted)
+	}
+	if len(counted) != 1 || counted[0] != 5 {
+		t.Error("unexpected counter", counted)
+	}
+}
+
+func TestStats(t *testing.T) {
+	var bus Events
+	ctx := context.Background()
+	bus.submitted.Subscribe(func(ctx context.Context, payload form) {
+		time.Sleep(time.Millisecond)
+	})
+	bus.submitted.Subscribe(func(ctx context.Context, payload form) {
+		if payload.Name == "fail" {
+			panic("broken handler")
+		}
+	})
+	child := bus.Child()
+	child.submitted.Subscribe(func(ctx context.Context, payload form) {})
+
+	_ = bus.submitted.Emit(ctx, form{Name: "first"})
+	_ = child.submitted.Emit(ctx, form{Name: "second"})
+	_ = bus.submitted.Emit(ctx, form{}) // invalid payload is not counted
+	func() {
+		defer func() { _ = recover() }()
+		_ = bus.submitted.Emit(ctx, form{Name: "fail"})
+	}()
+
+	stats := bus.Stats()["submitted"]
+	if stats.Emitted != 3 {
+		t.Error("expected 3 emitted events, got", stats.Emitted)
+	}
+	if stats.Subscribers != 3 {
+		t.Error("expected 3 subscribers (including child), got", stats.Subscribers)
+	}
+	if stats.Panics != 1 {
+		t.Error("expected 1 panic, got", stats.Panics)
+	}
+	if stats.Latency < 3*time.Millisecond || stats.LastLatency <= 0 {
+		t.Error("latency not collected", stats.Latency, stats.LastLatency)
+	}
+	if child.submitted.Stats() != stats {
+		t.Error("child should report stats of root", child.submitted.Stats())
+	}
+	if other := bus.Stats()["xxxx"]; other != (EventsEventStats{}) {
+		t.Error("stats of other event changed", other)
+	}
+}
+
+// register is a code under test that depends only on emitter interface
+func register(ctx context.Context, emitter EventsEmitter, name string) error {
+	if err := emitter.submitted(ctx, form{Name: name}); err != nil {
+		return err
+	}
+	emitter.UserCreated(ctx, entity{ID: len(name)})
+	return nil
+}
+
+func TestEmitterMock(t *testing.T) {
+	ctx := context.Background()
+	var mock EventsEmitterMock
+	if err := register(ctx, &mock, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if calls := mock.submittedCalls(); len(calls) != 1 || calls[0].Name != "alice" {
+		t.Error("unexpected submitted calls", calls)
+	}
+	if calls := mock.UserCreatedCalls(); len(calls) != 1 || calls[0].ID != 5 {
+		t.Error("unexpected created calls", calls)
+	}
+
+	mock.Reset()
+	mock.Err = errors.New("rejected")
+	if err := register(ctx, &mock, "bob"); err != mock.Err {
+		t.Error("expected error of mock, got", err)
+	}
+	if len(mock.submittedCalls()) != 1 || len(mock.UserCreatedCalls()) != 0 {
+		t.Error("calls should be reset and recorded until error", mock.submittedCalls(), mock.UserCreatedCalls())
+	}
+
+	var bus Events
+	var created []entity
+	bus.UserCreated.Subscribe(func(ctx context.Context, payload entity) {
+		created = append(created, payload)
+	})
+	if err := register(ctx, bus.Emitter(), ""); err == nil {
+		t.Error("emitter of bus should return validation error")
+	}
+	if err := register(ctx, bus.Emitter(), "carol"); err != nil {
+		t.Error(err)
+	}
+	if len(created) != 1 || created[0].ID != 5 {
+		t.Error("emitter of bus should dispatch events", created)
+	}
+}
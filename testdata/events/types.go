@@ -0,0 +1,64 @@
+// Package events contains payloads for tests of generated events. Generated code is compiled together with the
+// payloads in temporary module.
+package events
+
+import (
+	"context"
+	"errors"
+)
+
+// event:"xxxx"
+// event:"yyy"
+// something wrong event:"zzz"
+type event struct {
+	Name string
+}
+
+// event:"submitted"
+type form struct {
+	Name string
+}
+
+func (f form) Validate() error {
+	if f.Name == "" {
+		return errors.New("name required")
+	}
+	return nil
+}
+
+// event:"ids"
+type eventIDs []int64
+
+// event:"count"
+type counter int
+
+// event:"ask,reply=eventIDs"
+type query struct {
+	Limit int
+}
+
+func (q query) Validate() error {
+	if q.Limit < 0 {
+		return errors.New("negative limit")
+	}
+	return nil
+}
+
+// event:"User"
+// event:"UserCreated"
+// event:"UserRemoved"
+// event:"OrderRemoved"
+type entity struct {
+	ID int
+}
+
+// payload with the same name as generated tracing type
+// event:"traced"
+type Span struct {
+	ID string
+}
+
+type testEvents interface {
+	Created(event)
+	Removed(ctx context.Context, payload *event)
+}